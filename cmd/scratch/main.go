@@ -2,19 +2,40 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/levinishka/scratch/internal/generator"
+	"github.com/levinishka/scratch/internal/schema"
+)
+
+const (
+	configCommand       = "config"
+	configSchemaCommand = "schema"
 )
 
 func main() {
+	// run subcommand if needed
+	if len(os.Args) > 1 && os.Args[1] == configCommand {
+		runConfigCommand(os.Args[2:])
+		return
+	}
+
 	projectPathPtr := flag.String("project", "", `path to new project directory
 (last element in a path - project name)`)
 	repoPtr := flag.String("repo", "", `git repository path for new project
 (e.g. github.com/levinishka)`)
 	helpPtr := flag.Bool("help", false, "prints this message")
+	flag.Usage = func() {
+		output := flag.CommandLine.Output()
+		_, _ = fmt.Fprintf(output, "Usage of %s:\n", os.Args[0])
+		flag.PrintDefaults()
+		_, _ = fmt.Fprintf(output, "\nCommands:\n  %s %s\n    \tprints config schema of generated project\n",
+			configCommand, configSchemaCommand)
+	}
 	flag.Parse()
 
 	// print help if needed
@@ -38,3 +59,36 @@ func main() {
 
 	log.Printf("Project '%s' successfully created at %s", projectName, projectPath)
 }
+
+// runConfigCommand runs config subcommands
+func runConfigCommand(args []string) {
+	if len(args) == 0 || args[0] != configSchemaCommand {
+		log.Fatalf("unknown config command, use: %s %s %s -help", os.Args[0], configCommand, configSchemaCommand)
+	}
+
+	flagSet := flag.NewFlagSet(configCommand+" "+configSchemaCommand, flag.ExitOnError)
+	projectPathPtr := flagSet.String("project", ".", "path to generated project directory")
+	formatPtr := flagSet.String("format", schema.FormatJSON, `output format:
+json - JSON Schema for editor autocompletion
+markdown - reference table for documentation`)
+	packagePtr := flagSet.String("package", "internal/config", "path to config package inside project")
+	typePtr := flagSet.String("type", "Config", "name of config structure")
+	outputPtr := flagSet.String("output", "", "path to output file (stdout if empty)")
+	_ = flagSet.Parse(args[1:])
+
+	var output io.Writer = os.Stdout
+	if *outputPtr != "" {
+		file, err := os.Create(*outputPtr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		output = file
+	}
+
+	if err := schema.Generate(*projectPathPtr, *packagePtr, *typePtr, *formatPtr, output); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -18,10 +18,12 @@ var main = Element{
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
-	"time"
+
+	"go.uber.org/zap"
 
 	scratchConfig "github.com/levinishka/scratch/pkg/config"
 	"github.com/levinishka/scratch/pkg/logger"
@@ -30,35 +32,99 @@ import (
 	scratchServer "github.com/levinishka/scratch/pkg/server"
 	cfg "{{ .RepoPath }}/{{ .ProjectName }}/internal/config"
 	"{{ .RepoPath }}/{{ .ProjectName }}/internal/handler"
+	"{{ .RepoPath }}/{{ .ProjectName }}/internal/metrics"
 )
 
-const configFileName = "config.json"
+const (
+	defaultConfigFileName = "config.json"
+	// envPrefix is prepended to environment variables which override config values, e.g. LISTEN_PORT
+	envPrefix = ""
+)
 
 func main() {
 	const fn = "main"
 
-	// read config
-	config := cfg.Config{}
-	if err := scratchConfig.NewConfig(configFileName, &config); err != nil {
+	configFilePtr := flag.String("config", defaultConfigFileName, "path to config file in JSON, YAML or TOML format")
+	envPtr := flag.String("env", "", ` + "`" + `environment name: config.<env>.json next to config file
+overrides its values (e.g. production)` + "`" + `)
+	// every config field can also be overridden with flag, e.g. -listen_port 10002
+	configFlags, err := scratchConfig.RegisterFlags(flag.CommandLine, &cfg.Config{})
+	if err != nil {
+		log.Fatalf("%s: unable to register config flags: %v", fn, err)
+	}
+	flag.Parse()
+
+	// read config layers: defaults, config file, environment file, environment variables and flags
+	configLoader := scratchConfig.Loader{
+		ConfigFile:  *configFilePtr,
+		Environment: *envPtr,
+		UseEnv:      true,
+		EnvPrefix:   envPrefix,
+		Flags:       configFlags,
+	}
+	configWatcher, err := scratchConfig.NewLoaderWatcher[cfg.Config](&configLoader)
+	if err != nil {
 		log.Fatalf("%s: unable to get new config: %v", fn, err)
 	}
-	log.Printf("%s: config: %+v", fn, config)
+	config := configWatcher.Config()
+	log.Printf("%s: config: %v", fn, scratchConfig.Describe(config))
 
 	// get new logger
-	sugarLogger, err := logger.NewEnvironmentSugarLogger(config.LogEnv, config.PathsToLogs)
+	loggerOptions := []logger.Option{
+		logger.WithEnvironment(config.LogEnv),
+		logger.WithLevel(config.LogLevel),
+		logger.WithOutputs(config.PathsToLogs...),
+		logger.WithLimits(config.LogLimits),
+		logger.WithAsync(config.LogAsync),
+		logger.WithFields(zap.String("service", "{{ .ProjectName }}")),
+		// count log messages by levels in log_messages_total metric
+		logger.WithMetrics(scratchMetrics.LogMessagesTotal),
+	}
+	if config.LogFormat != "" {
+		logEncoder, err := logger.EncoderPreset(config.LogFormat)
+		if err != nil {
+			log.Fatalf("%s: unable to get log encoder: %v", fn, err)
+		}
+		loggerOptions = append(loggerOptions, logger.WithEncoder(logEncoder))
+	}
+	loggerHandle, err := logger.New(loggerOptions...)
 	if err != nil {
 		log.Fatalf("%s: unable to get new logger: %v", fn, err)
 	}
+	sugarLogger := loggerHandle.Sugar()
 	defer func() {
-		_ = sugarLogger.Sync()
+		_ = loggerHandle.Close()
 	}()
 	// duplicate config printing to config.PathToLogs
-	sugarLogger.Infof("%s: config: %+v", fn, config)
+	sugarLogger.Infow(fmt.Sprintf("%s: config", fn), zap.Dict("config", scratchConfig.DescribeFields(config)...))
+
+	// apply reloaded config values which can be changed without restart
+	configWatcher.Subscribe(func(oldConfig, newConfig *cfg.Config) {
+		if newConfig.LogLevel != "" && newConfig.LogLevel != oldConfig.LogLevel {
+			level, ok := logger.LevelNamesMap[newConfig.LogLevel]
+			if !ok {
+				sugarLogger.Errorf("%s: unable to change log level: unknown level %q", fn, newConfig.LogLevel)
+				return
+			}
+			loggerHandle.Level().SetLevel(level)
+			sugarLogger.Infof("%s: log level changed to %s", fn, newConfig.LogLevel)
+		}
+	})
+	if err := configWatcher.Start(sugarLogger); err != nil {
+		sugarLogger.Errorf("%s: unable to watch config: %v", fn, err)
+	}
 
 	mainContext := context.Background()
 
-	// setting routes
-	router := scratchRouter.NewRouterWithPprof(true)
+	// prometheus registry with http, go runtime, process and build metrics, register own metrics in it
+	metricsRegistry := scratchMetrics.NewRegistry()
+	metrics.Register(metricsRegistry)
+
+	// setting routes, log level can be changed at /debug/loglevel
+	// every request gets its own logger with request ID, use logger.FromContext in handlers,
+	// and is written to access log
+	router := scratchRouter.NewRouterWithPprof(true, scratchRouter.WithLogLevel(loggerHandle.Level()),
+		scratchRouter.WithLogger(sugarLogger), scratchRouter.WithMetrics(metricsRegistry))
 
 	// get new handler constructor
 	handlerConstructor := handler.NewConstructor(sugarLogger)
@@ -71,23 +137,34 @@ func main() {
 	router.Handle("/repeat", http.HandlerFunc(repeatHandler)).Methods(http.MethodPost)
 	router.Handle("/repeatJSON", http.HandlerFunc(repeatJSONHandler)).Methods(http.MethodPost)
 
-	// start prometheus
-	go func() {
-		metricsAddr := fmt.Sprintf("%s:%d", config.ListenHost, config.MetricsPort)
-		sugarLogger.Infof("Starting metrics server at %s", metricsAddr)
-		if err := scratchMetrics.RunMetricsServer(metricsAddr); err != nil {
-			sugarLogger.Errorf("metrics server error: %v", err)
+	// start prometheus metrics server, metrics are served on main router if metrics port is 0
+	metricsServer := scratchMetrics.NewServer(fmt.Sprintf("%s:%d", config.ListenHost, config.MetricsPort),
+		scratchMetrics.WithRegistry(metricsRegistry),
+		scratchMetrics.WithBearerToken(config.MetricsBearerToken.Value()),
+		scratchMetrics.WithTLS(config.MetricsTLSCertFile, config.MetricsTLSKeyFile),
+		scratchMetrics.WithLogger(sugarLogger),
+	)
+	if config.MetricsPort == 0 {
+		if config.MetricsTLSCertFile != "" || config.MetricsTLSKeyFile != "" {
+			sugarLogger.Warnf("%s: metrics TLS files are ignored, metrics are served by service's http server", fn)
 		}
-	}()
+		router.Handle(scratchMetrics.MetricsPath, metricsServer.Handler()).Methods(http.MethodGet)
+	} else {
+		if err := metricsServer.Start(); err != nil {
+			sugarLogger.Fatalf("%s: unable to start metrics server: %v", fn, err)
+		}
+		sugarLogger.Infof("%s: Starting metrics server at %s", fn, metricsServer.Addr())
+	}
 
 	// starting server
 	server := scratchServer.NewServer(&http.Server{
-		Addr:        fmt.Sprintf("%s:%d", config.ListenHost, config.ListenPort),
-		Handler:     router,
-		ReadTimeout: time.Duration(config.ReadTimeout) * time.Second,
-	}, sugarLogger, config.GracefulShutdownTimeout)
+		Addr:           fmt.Sprintf("%s:%d", config.ListenHost, config.ListenPort),
+		Handler:        router,
+		ReadTimeout:    config.ReadTimeout.Duration(),
+		MaxHeaderBytes: config.MaxHeaderBytes.Int(),
+	}, sugarLogger, config.GracefulShutdownTimeout.Duration())
 
-	server.Run(mainContext, func() {})
+	server.Run(mainContext, configWatcher.Close, metricsServer.Stop)
 
 	sugarLogger.Infof("%s: Bye :)", fn)
 }
@@ -106,6 +183,23 @@ To run service:
 make build
 make test-run
 ` + "```" + `
+Config is read from layers, every next layer overrides previous ones:
+defaults from ` + "`default`" + ` struct tags, config file, environment file, environment variables and flags
+` + "```" + `shell
+# reads config.json and then config.production.json
+./cmd/bin/{{ .ProjectName }} -config config.json -env production
+# any config field can be overridden with environment variable or flag
+LISTEN_PORT=10002 ./cmd/bin/{{ .ProjectName }} -log_level debug
+# lists are comma separated, use JSON array when items contain commas
+PATHS_TO_LOGS='["stderr", "rotate:logs/log?max_size=100MiB&max_backups=10"]' ./cmd/bin/{{ .ProjectName }}
+` + "```" + `
+
+Config reference and JSON Schema can be generated with scratch
+` + "```" + `shell
+scratch config schema -format markdown -output CONFIG.md
+scratch config schema -output config.schema.json
+` + "```" + `
+
 To test service:
 ` + "```" + `shell
 curl -d '' localhost:10001/
@@ -113,6 +207,18 @@ curl -d 'text=some text here to repeat' localhost:10001/repeat
 curl -H 'Content-Type: application/json' -d '{"text": "some text here to repeat"}' localhost:10001/repeatJSON
 ` + "```" + `
 
+Log level can be changed without restart, optional ttl reverts it back
+` + "```" + `shell
+curl localhost:10001/debug/loglevel
+curl -X PUT -d '{"level": "debug", "ttl": "10m"}' localhost:10001/debug/loglevel
+` + "```" + `
+
+Prometheus metrics are served at localhost:8081/metrics, set ` + "`metrics_port`" + ` to 0 to serve them at localhost:10001/metrics,
+` + "`metrics_bearer_token`" + ` protects them and ` + "`metrics_tls_cert_file`" + ` and ` + "`metrics_tls_key_file`" + ` enable https
+` + "```" + `shell
+curl localhost:8081/metrics
+` + "```" + `
+
 ## Development
 Before commit run
 ` + "```" + `shell
@@ -132,10 +238,15 @@ logs
 	{
 		FileName: "Makefile",
 		FilePath: "/",
-		Template: `build:
+		Template: `# version and commit are shown by build_info metric
+VERSION ?= $(shell git describe --tags --always --dirty 2>/dev/null || echo dev)
+COMMIT ?= $(shell git rev-parse HEAD 2>/dev/null || echo unknown)
+LDFLAGS := -X github.com/levinishka/scratch/pkg/metrics.Version=$(VERSION) -X github.com/levinishka/scratch/pkg/metrics.Commit=$(COMMIT)
+
+build:
 	mkdir -p cmd/bin
 	mkdir -p logs
-	go build -o cmd/bin/{{ .ProjectName }} cmd/{{ .ProjectName }}/main.go
+	go build -ldflags "$(LDFLAGS)" -o cmd/bin/{{ .ProjectName }} ./cmd/{{ .ProjectName }}
 
 clean:
 	rm -rf cmd/bin
@@ -161,10 +272,20 @@ test:
   "listen_host": "localhost",
   "listen_port": 10001,
   "metrics_port": 8081,
-  "http_read_timeout_sec": 5,
-  "graceful_shutdown_timeout_sec": 5,
-  "paths_to_logs": ["logs/log"],
-  "log_env": "production"
+  "http_read_timeout": "5s",
+  "http_max_header_bytes": "1MiB",
+  "graceful_shutdown_timeout": "5s",
+  "paths_to_logs": ["rotate:logs/log?max_size=100MiB&max_age=168h&max_backups=10&compress=true"],
+  "log_env": "production",
+  "log_level": "info",
+  "log_format": "default",
+  "log_limits": {
+    "sampling": {
+      "debug": {"initial": 100, "thereafter": 100, "tick": "1s"},
+      "info": {"initial": 100, "thereafter": 100, "tick": "1s"}
+    },
+    "rate_limit": {"burst": 100, "interval": "1s"}
+  }
 }
 `,
 	},
@@ -173,22 +294,46 @@ test:
 		FilePath: "internal/config",
 		Template: `package config
 
+import (
+	scratchConfig "github.com/levinishka/scratch/pkg/config"
+	"github.com/levinishka/scratch/pkg/logger"
+)
+
 // Config stores all values from text config to run service
+// use scratchConfig.Secret type for passwords and tokens:
+// values like file:///run/secrets/db_pass or env://DB_PASS are resolved at load time and never printed
 type Config struct {
 	// ListenHost stores host for service's http server
-	` + "ListenHost              string `json:\"listen_host\"`\n" +
+	` + "ListenHost              string `json:\"listen_host\" default:\"localhost\" description:\"host of service's http server\"`\n" +
 			"	// ListenPort stores port for service's http server\n" +
-			"	ListenPort              int64  `json:\"listen_port\"`\n" +
+			"	ListenPort              int64  `json:\"listen_port\" default:\"10001\" validate:\"min=1,max=65535\" description:\"port of service's http server\"`\n" +
 			"	// MetricsPort stores port for service's prometheus metric http server\n" +
-			"	MetricsPort             int64  `json:\"metrics_port\"`\n" +
+			"	MetricsPort             int64  `json:\"metrics_port\" default:\"8081\" validate:\"min=0,max=65535\" description:\"port of prometheus metrics http server, metrics are served by service's http server if it is 0\"`\n" +
+			"	// MetricsBearerToken protects metrics with bearer token, e.g. file:///run/secrets/metrics_token\n" +
+			"	MetricsBearerToken      scratchConfig.Secret `json:\"metrics_bearer_token\" description:\"bearer token which protects metrics, metrics are not protected if it is empty\"`\n" +
+			"	// MetricsTLSCertFile and MetricsTLSKeyFile enable https of prometheus metric http server\n" +
+			"	MetricsTLSCertFile      string `json:\"metrics_tls_cert_file\" validate:\"omitempty,file-exists\" description:\"TLS certificate file of metrics http server\"`\n" +
+			"	MetricsTLSKeyFile       string `json:\"metrics_tls_key_file\" validate:\"omitempty,file-exists\" description:\"TLS key file of metrics http server\"`\n" +
 			"	// ReadTimeout stores timeout for service's http server\n" +
-			"	ReadTimeout             int64  `json:\"http_read_timeout_sec\"`\n" +
+			"	ReadTimeout             scratchConfig.Duration `json:\"http_read_timeout\" default:\"5s\" validate:\"min=0s\" description:\"read timeout of http server\"`\n" +
+			"	// MaxHeaderBytes stores maximum size of request headers for service's http server\n" +
+			"	MaxHeaderBytes          scratchConfig.ByteSize `json:\"http_max_header_bytes\" default:\"1MiB\" validate:\"min=1KiB\" description:\"maximum size of request headers\"`\n" +
 			"	// GracefulShutdownTimeout stores time which is given to service to gracefully shutdown resources\n" +
-			"	GracefulShutdownTimeout int64  `json:\"graceful_shutdown_timeout_sec\"`\n\n" +
-			"	// PathsToLogs stores paths where logger will write: can be any valid path to file or stdout/stderr\n" +
-			"	PathsToLogs []string `json:\"paths_to_logs\"`\n" +
+			"	GracefulShutdownTimeout scratchConfig.Duration `json:\"graceful_shutdown_timeout\" default:\"5s\" validate:\"min=0s\" description:\"time given to gracefully shutdown resources\"`\n\n" +
+			"	// PathsToLogs stores paths where logger will write: can be any valid path to file, stdout/stderr\n" +
+			"	// or rotating file like rotate:logs/log?max_size=100MiB&max_backups=10&compress=true,\n" +
+			"	// syslog like syslog+udp://localhost:514 or http collector like https+json://loki:3100/loki/api/v1/push?format=loki\n" +
+			"	PathsToLogs []string `json:\"paths_to_logs\" description:\"paths where logger writes: files, rotating files (rotate:path?max_size=100MiB), syslog (syslog+udp://host:514), http collectors (https+json://host/path), stdout or stderr\"`\n" +
 			"	// LogEnv stores service's environment, which can be used for resources initialization\n" +
-			"	LogEnv      string `json:\"log_env\"`\n" +
+			"	LogEnv      string `json:\"log_env\" default:\"production\" validate:\"omitempty,oneof=development production\" description:\"environment used for resources initialization\"`\n" +
+			"	// LogLevel stores logger's level, it can be changed without restart by editing config file or sending SIGHUP\n" +
+			"	LogLevel    string `json:\"log_level\" validate:\"omitempty,oneof=debug info warn error dpanic panic fatal\" description:\"logger level, can be changed without restart\"`\n" +
+			"	// LogFormat stores logger's format preset: default, ecs (Elastic Common Schema), gcp (GCP Cloud Logging) or logfmt\n" +
+			"	LogFormat   string `json:\"log_format\" validate:\"omitempty,oneof=default ecs gcp logfmt\" description:\"logger format preset, console is used in development if it is empty\"`\n" +
+			"	// LogLimits stores logger's sampling and rate limiting, environment preset is used if it is not set\n" +
+			"	LogLimits   *logger.Limits `json:\"log_limits\" description:\"logger sampling by levels and rate limiting of similar messages\"`\n" +
+			"	// LogAsync enables asynchronous writing of logs, e.g. {\"buffer_size\": 1024, \"flush_interval\": \"1s\", \"drop_when_full\": true}\n" +
+			"	LogAsync    *logger.AsyncOptions `json:\"log_async\" description:\"asynchronous writing of logs, logs are written synchronously if it is not set\"`\n" +
 			`}
 `,
 	},
@@ -205,11 +350,14 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/levinishka/scratch/pkg/logger"
 	"go.uber.org/zap"
 )
 
 type Constructor struct {
 	/*if you need object in all handlers - pass it here*/
+	// Logger is a service logger, use logger.FromContext(req.Context()) in handlers
+	// to get request logger with request ID
 	Logger *zap.SugaredLogger
 }
 
@@ -241,21 +389,21 @@ func (c *Constructor) GetRepeatHandler( /*pass all needed objects for handler he
 		// read body
 		body, _ := ioutil.ReadAll(req.Body)
 		if len(body) == 0 {
-			handleError(respWriter, http.StatusBadRequest, c.Logger, "Unable to get request body", nil)
+			handleError(respWriter, req, http.StatusBadRequest, "Unable to get request body", nil)
 			return
 		}
 
 		// parse body parameters
 		values, err := url.ParseQuery(string(body))
 		if err != nil {
-			handleError(respWriter, http.StatusBadRequest, c.Logger, "Unable to parse request body", err)
+			handleError(respWriter, req, http.StatusBadRequest, "Unable to parse request body", err)
 			return
 		}
 
 		// get text parameter
 		text := values.Get("text")
 		if len(text) == 0 {
-			handleError(respWriter, http.StatusBadRequest, c.Logger, "Unable to get text parameter", err)
+			handleError(respWriter, req, http.StatusBadRequest, "Unable to get text parameter", err)
 			return
 		}
 
@@ -263,6 +411,7 @@ func (c *Constructor) GetRepeatHandler( /*pass all needed objects for handler he
 		start := time.Now()
 		time.Sleep(500 * time.Millisecond)
 		elapsed := time.Since(start).Milliseconds()
+		logger.FromContext(req.Context()).Debugw("text repeated", "elapsed_ms", elapsed)
 
 		resp := Response{
 			Text:          text,
@@ -272,7 +421,7 @@ func (c *Constructor) GetRepeatHandler( /*pass all needed objects for handler he
 		// marshal and send response
 		respJSON, err := json.Marshal(resp)
 		if err != nil {
-			handleError(respWriter, http.StatusInternalServerError, c.Logger, "Unable to marshal response", err)
+			handleError(respWriter, req, http.StatusInternalServerError, "Unable to marshal response", err)
 			return
 		}
 
@@ -285,7 +434,7 @@ func (c *Constructor) GetRepeatJSONHandler( /*pass all needed objects for handle
 	return func(respWriter http.ResponseWriter, req *http.Request) {
 		var r Request
 		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
-			handleError(respWriter, http.StatusBadRequest, c.Logger, "Unable to decode body", err)
+			handleError(respWriter, req, http.StatusBadRequest, "Unable to decode body", err)
 			return
 		}
 
@@ -293,6 +442,7 @@ func (c *Constructor) GetRepeatJSONHandler( /*pass all needed objects for handle
 		start := time.Now()
 		time.Sleep(500 * time.Millisecond)
 		elapsed := time.Since(start).Milliseconds()
+		logger.FromContext(req.Context()).Debugw("text repeated", "elapsed_ms", elapsed)
 
 		resp := Response{
 			Text:          r.Text,
@@ -302,7 +452,7 @@ func (c *Constructor) GetRepeatJSONHandler( /*pass all needed objects for handle
 		// marshal and send response
 		respJSON, err := json.Marshal(resp)
 		if err != nil {
-			handleError(respWriter, http.StatusInternalServerError, c.Logger, "Unable to marshal response", err)
+			handleError(respWriter, req, http.StatusInternalServerError, "Unable to marshal response", err)
 			return
 		}
 
@@ -310,11 +460,11 @@ func (c *Constructor) GetRepeatJSONHandler( /*pass all needed objects for handle
 	}
 }
 
-// handleError sends error as response and log it
-func handleError(respWriter http.ResponseWriter, status int, logger *zap.SugaredLogger, errText string, err error) {
+// handleError sends error as response and log it with request logger
+func handleError(respWriter http.ResponseWriter, req *http.Request, status int, errText string, err error) {
 	e := fmt.Sprintf("%d (%s): %s: %v", status, http.StatusText(status), errText, err)
 	http.Error(respWriter, e, status)
-	logger.Errorf("%v", e)
+	logger.FromContext(req.Context()).Errorf("%v", e)
 }
 `,
 	},
@@ -323,15 +473,18 @@ func handleError(respWriter http.ResponseWriter, status int, logger *zap.Sugared
 		FilePath: "internal/metrics",
 		Template: `package metrics
 
+import (
+	scratchMetrics "github.com/levinishka/scratch/pkg/metrics"
+)
+
 // Use this package to create your own prometheus metrics
 // for example:
 //
 // import (
 // 	"github.com/prometheus/client_golang/prometheus"
-// 	"github.com/prometheus/client_golang/prometheus/promauto"
 // )
 //
-// var MyNewCustomMetric = promauto.NewCounterVec(
+// var MyNewCustomMetric = prometheus.NewCounterVec(
 // 	prometheus.CounterOpts{
 // 		Name: "my_new_custom_metric",
 // 		Help: "This is my new custom metric",
@@ -339,8 +492,13 @@ func handleError(respWriter http.ResponseWriter, status int, logger *zap.Sugared
 // 	[]string{"label"},
 // )
 //
-// You can check basics metrics in github.com/levinishka/scratch/pkg/metrics/metrics.go
+// You can check basics metrics in github.com/levinishka/scratch/pkg/metrics/registry.go
 
+// Register registers service metrics in registry, which serves them with basic metrics
+// add your metrics here, e.g. registry.MustRegister(MyNewCustomMetric)
+func Register(registry *scratchMetrics.Registry) {
+	registry.MustRegister()
+}
 `,
 	},
 }
@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+
+	// schemaDirPattern is a pattern of temporary directory inside project,
+	// leading dot hides it from ./... patterns of go tool
+	schemaDirPattern = ".scratch-schema-*"
+)
+
+// Parameters stores values for schema program template
+type Parameters struct {
+	// Module is a module path of project from go.mod
+	Module string
+	// ConfigPackage is a path to config package inside project
+	ConfigPackage string
+	// ConfigType is a name of config structure
+	ConfigType string
+	// Format is an output format: json or markdown
+	Format string
+	// Title is a schema and reference title
+	Title string
+}
+
+// schemaProgram is a program which is run inside project to reflect over its config structure
+const schemaProgram = `package main
+
+import (
+	"fmt"
+	"log"
+
+	scratchConfig "github.com/levinishka/scratch/pkg/config"
+	cfg "{{ .Module }}/{{ .ConfigPackage }}"
+)
+
+func main() {
+	{{- if eq .Format "markdown" }}
+	reference, err := scratchConfig.MarkdownReference(&cfg.{{ .ConfigType }}{}, "{{ .Title }}")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(reference)
+	{{- else }}
+	schema, err := scratchConfig.MarshalJSONSchema(&cfg.{{ .ConfigType }}{}, "{{ .Title }}")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(schema))
+	{{- end }}
+}
+`
+
+// Generate writes JSON Schema or Markdown reference of config structure of project at projectPath to output
+// schema is built by temporary program which is run with go tool inside project
+func Generate(projectPath string, configPackage string, configType string, format string, output io.Writer) error {
+	if format != FormatJSON && format != FormatMarkdown {
+		return fmt.Errorf("unknown schema format %q, use %s or %s", format, FormatJSON, FormatMarkdown)
+	}
+
+	absProjectPath, err := filepath.Abs(projectPath)
+	if err != nil {
+		return err
+	}
+
+	module, err := modulePath(filepath.Join(absProjectPath, "go.mod"))
+	if err != nil {
+		return err
+	}
+
+	parameters := Parameters{
+		Module:        module,
+		ConfigPackage: strings.Trim(filepath.ToSlash(configPackage), "/"),
+		ConfigType:    configType,
+		Format:        format,
+		Title:         filepath.Base(absProjectPath) + " config",
+	}
+
+	// create program inside project, so it uses project's go.mod
+	schemaDir, err := os.MkdirTemp(absProjectPath, schemaDirPattern)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(schemaDir)
+	}()
+
+	if err := writeProgram(filepath.Join(schemaDir, "main.go"), parameters); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", "./"+filepath.Base(schemaDir))
+	cmd.Dir = absProjectPath
+	cmd.Stdout = output
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("unable to build config schema: %v", err)
+	}
+
+	return nil
+}
+
+// writeProgram writes schema program with parameters to fileName
+func writeProgram(fileName string, parameters Parameters) error {
+	programTemplate, err := template.New("schema program").Parse(schemaProgram)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return programTemplate.Execute(file, parameters)
+}
+
+// modulePath reads module path from goModFile
+func modulePath(goModFile string) (string, error) {
+	file, err := os.Open(goModFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read go.mod, initialize go modules first: %v", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if module, ok := strings.CutPrefix(line, "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", fmt.Errorf("module path is not found in %s", goModFile)
+}
@@ -1,24 +1,95 @@
 package config
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"os"
 )
 
-// NewConfig reads config from configFile
+// NewConfig reads config from configFile, resolves secret references with DefaultSecretResolver
+// and validates it with rules from validate struct tags
+// format is chosen by file extension: .json, .yaml, .yml or .toml
 // config must be reference to your config structure
 func NewConfig(configFile string, config interface{}) error {
 	const fn = "config.NewConfig"
 
-	file, err := os.ReadFile(configFile)
+	format, err := FormatFromFile(configFile)
 	if err != nil {
-		return fmt.Errorf("%s: unable to read config file: %v", fn, err)
+		return fmt.Errorf("%s: %v", fn, err)
 	}
 
-	err = json.Unmarshal(file, config)
+	return NewConfigWithFormat(configFile, format, config)
+}
+
+// NewStrictConfig works like NewConfig, but also fails if configFile contains keys
+// which don't match any field of config
+func NewStrictConfig(configFile string, config interface{}) error {
+	const fn = "config.NewStrictConfig"
+
+	format, err := FormatFromFile(configFile)
 	if err != nil {
-		return fmt.Errorf("%s: unable to unmarshal config: %v", fn, err)
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if err := readConfig(configFile, format, true, config); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if err := completeConfig(config, nil); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// NewConfigWithFormat reads config in format from configFile regardless of its extension,
+// resolves secret references and validates it with rules from validate struct tags
+// config must be reference to your config structure
+func NewConfigWithFormat(configFile string, format Format, config interface{}) error {
+	const fn = "config.NewConfigWithFormat"
+
+	if err := readConfig(configFile, format, false, config); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if err := completeConfig(config, nil); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// completeConfig resolves secret references of decoded config with resolver and validates it
+func completeConfig(config interface{}, resolver SecretResolver) error {
+	if err := ResolveSecrets(config, resolver); err != nil {
+		return err
+	}
+
+	return Validate(config)
+}
+
+// readConfig reads and decodes configFile in format to config
+// if strict is true, unknown keys are treated as error
+func readConfig(configFile string, format Format, strict bool, config interface{}) error {
+	return readProvider(context.Background(), &FileProvider{Path: configFile, Format: format}, strict, config)
+}
+
+// readJSON reads configFile in format and converts it to JSON
+func readJSON(configFile string, format Format) ([]byte, error) {
+	return readProviderJSON(context.Background(), &FileProvider{Path: configFile, Format: format})
+}
+
+// decodeJSON unmarshals data to config
+// if strict is true, unknown keys are treated as error
+func decodeJSON(data []byte, strict bool, config interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("unable to unmarshal config: %v", err)
 	}
 
 	return nil
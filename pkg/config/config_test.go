@@ -46,3 +46,74 @@ func TestNewConfig(t *testing.T) {
 		})
 	}
 }
+
+type testConfig struct {
+	ListenHost   string `json:"listen_host"`
+	ListenPort   int64  `json:"listen_port"`
+	ReadTimeout  int64  `json:"http_read_timeout_sec"`
+	WriteTimeout int64  `json:"http_write_timeout_sec"`
+
+	GracefulShutdownTimeout int64 `json:"graceful_shutdown_timeout_sec"`
+
+	LogLevel   string `json:"log_level"`
+	PathToLogs string `json:"path_to_logs"`
+}
+
+var wantTestConfig = testConfig{
+	ListenHost:              "localhost",
+	ListenPort:              10001,
+	ReadTimeout:             5,
+	WriteTimeout:            5,
+	GracefulShutdownTimeout: 5,
+	LogLevel:                "debug",
+	PathToLogs:              "logs/log",
+}
+
+func TestNewConfigFormats(t *testing.T) {
+	tests := []struct {
+		name       string
+		configFile string
+		wantErr    bool
+	}{
+		{"json", "test_files/config1.json", false},
+		{"yaml", "test_files/config1.yaml", false},
+		{"toml", "test_files/config1.toml", false},
+		{"unknown extension", "test_files/config1.conf", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig{}
+			err := NewConfig(tt.configFile, &config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && config != wantTestConfig {
+				t.Errorf("NewConfig() got = %+v, want %+v", config, wantTestConfig)
+			}
+		})
+	}
+}
+
+func TestNewConfigWithFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  Format
+		wantErr bool
+	}{
+		{"yaml", FormatYAML, false},
+		{"json", FormatJSON, true},
+		{"unknown", Format("ini"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig{}
+			err := NewConfigWithFormat("test_files/config1.conf", tt.format, &config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewConfigWithFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && config != wantTestConfig {
+				t.Errorf("NewConfigWithFormat() got = %+v, want %+v", config, wantTestConfig)
+			}
+		})
+	}
+}
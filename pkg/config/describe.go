@@ -0,0 +1,146 @@
+package config
+
+import (
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// describedField is a config field with its json name
+// nested is set instead of value for nested structures
+type describedField struct {
+	name   string
+	value  interface{}
+	nested []describedField
+}
+
+// Describe returns config as a map with json names as keys and nested maps for nested structures,
+// values of secret fields (Secret type or `secret:"true"` tag) are masked
+// config must be structure or reference to it
+func Describe(config interface{}) map[string]interface{} {
+	return fieldsToMap(describe(config))
+}
+
+// DescribeFields returns config as zap fields with json names as keys,
+// nested structures are returned as zap.Dict and values of secret fields are masked
+// config must be structure or reference to it
+func DescribeFields(config interface{}) []zap.Field {
+	return fieldsToZap(describe(config))
+}
+
+// describe walks config structure
+func describe(config interface{}) []describedField {
+	value := reflect.ValueOf(config)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return describeStruct(value)
+}
+
+// describeStruct describes all fields of structure in their order
+func describeStruct(structValue reflect.Value) []describedField {
+	structType := structValue.Type()
+	fields := make([]describedField, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if _, ok := embeddedStructType(field); ok {
+			if embedded := reflect.Indirect(structValue.Field(i)); embedded.IsValid() {
+				fields = append(fields, describeStruct(embedded)...)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		described := describedField{name: name}
+		fieldValue := reflect.Indirect(structValue.Field(i))
+		switch {
+		case !fieldValue.IsValid():
+			described.value = nil
+		case IsSecretField(field):
+			described.value = maskSecret(fieldValue)
+		case isNestedStruct(fieldValue.Type()):
+			described.nested = describeStruct(fieldValue)
+		default:
+			described.value = describeValue(fieldValue)
+		}
+
+		fields = append(fields, described)
+	}
+
+	return fields
+}
+
+// describeValue returns value which is readable in logs
+func describeValue(value reflect.Value) interface{} {
+	switch {
+	case value.Type() == durationType:
+		return time.Duration(value.Int()).String()
+	case value.Kind() == reflect.Slice && value.IsNil():
+		return nil
+	case (value.Kind() == reflect.Slice || value.Kind() == reflect.Array) && isNestedStruct(value.Type().Elem()):
+		elems := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			elems = append(elems, fieldsToMap(describeStruct(value.Index(i))))
+		}
+		return elems
+	default:
+		return value.Interface()
+	}
+}
+
+// maskSecret returns masked secret value
+func maskSecret(value reflect.Value) interface{} {
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		masked := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			masked = append(masked, Secret(reflect.Indirect(value.Index(i)).String()).String())
+		}
+		return masked
+	}
+
+	if value.Kind() != reflect.String {
+		return secretMask
+	}
+
+	return Secret(value.String()).String()
+}
+
+// fieldsToMap converts described fields to map
+func fieldsToMap(fields []describedField) map[string]interface{} {
+	result := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if field.nested != nil {
+			result[field.name] = fieldsToMap(field.nested)
+			continue
+		}
+		result[field.name] = field.value
+	}
+
+	return result
+}
+
+// fieldsToZap converts described fields to zap fields
+func fieldsToZap(fields []describedField) []zap.Field {
+	result := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		if field.nested != nil {
+			result = append(result, zap.Dict(field.name, fieldsToZap(field.nested)...))
+			continue
+		}
+		result = append(result, zap.Any(field.name, field.value))
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type describeTestConfig struct {
+	ListenPort  int64         `json:"listen_port"`
+	Timeout     time.Duration `json:"timeout"`
+	PathsToLogs []string      `json:"paths_to_logs"`
+	DBPassword  Secret        `json:"db_password"`
+	APIToken    string        `json:"api_token" secret:"true"`
+	EmptyToken  Secret        `json:"empty_token"`
+	Skipped     string        `json:"-"`
+	DB          struct {
+		Host string `json:"host"`
+	} `json:"db"`
+	Cache *struct {
+		Host string `json:"host"`
+	} `json:"cache"`
+}
+
+func newDescribeTestConfig() describeTestConfig {
+	config := describeTestConfig{
+		ListenPort:  10001,
+		Timeout:     250 * time.Millisecond,
+		PathsToLogs: []string{"stderr"},
+		DBPassword:  "db-secret",
+		APIToken:    "api-secret",
+		Skipped:     "skipped",
+	}
+	config.DB.Host = "db"
+
+	return config
+}
+
+func TestDescribe(t *testing.T) {
+	want := map[string]interface{}{
+		"listen_port":   int64(10001),
+		"timeout":       "250ms",
+		"paths_to_logs": []string{"stderr"},
+		"db_password":   secretMask,
+		"api_token":     secretMask,
+		"empty_token":   "",
+		"db":            map[string]interface{}{"host": "db"},
+		"cache":         nil,
+	}
+
+	config := newDescribeTestConfig()
+	if got := Describe(&config); !reflect.DeepEqual(got, want) {
+		t.Errorf("Describe() = %v, want %v", got, want)
+	}
+}
+
+func TestDescribeFields(t *testing.T) {
+	config := newDescribeTestConfig()
+
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range DescribeFields(config) {
+		field.AddTo(encoder)
+	}
+
+	if got := encoder.Fields["db_password"]; got != secretMask {
+		t.Errorf("DescribeFields() db_password = %v, want masked", got)
+	}
+	if got := encoder.Fields["db"]; !reflect.DeepEqual(got, map[string]interface{}{"host": "db"}) {
+		t.Errorf("DescribeFields() db = %v", got)
+	}
+	if _, ok := encoder.Fields["Skipped"]; ok {
+		t.Errorf("DescribeFields() must skip fields ignored by json")
+	}
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const envSeparator = "_"
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// NewConfigWithEnv reads config from configFile, overrides its values with environment variables,
+// resolves secret references and then validates result with rules from validate struct tags
+// see ApplyEnv for details about variable names
+func NewConfigWithEnv(configFile string, envPrefix string, config interface{}) error {
+	const fn = "config.NewConfigWithEnv"
+
+	format, err := FormatFromFile(configFile)
+	if err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if err := readConfig(configFile, format, false, config); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if err := ApplyEnv(envPrefix, config); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if err := completeConfig(config, nil); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// ApplyEnv overrides fields of config with environment variables
+// config must be reference to your config structure
+//
+// variable name is built from field's json tag in upper case: listen_port -> LISTEN_PORT,
+// for nested structures names are joined with underscore: db.host -> DB_HOST,
+// if envPrefix is not empty it is prepended the same way: APP_LISTEN_PORT.
+// Slices are read as comma separated lists or JSON arrays, maps as JSON objects,
+// durations in time.ParseDuration format
+func ApplyEnv(envPrefix string, config interface{}) error {
+	const fn = "config.ApplyEnv"
+
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%s: config must be non-nil pointer to structure, got %T", fn, config)
+	}
+
+	if err := applyEnv(envPrefix, value.Elem(), nil); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	return nil
+}
+
+// applyEnv applies environment variables to config structure
+// and marks applied fields in sources if it isn't nil
+func applyEnv(envPrefix string, structValue reflect.Value, sources Sources) error {
+	_, err := applyEnvStruct(structValue, strings.ToUpper(envPrefix), "", sources)
+	return err
+}
+
+// EnvName returns environment variable name for field with jsonName inside structure with prefix
+func EnvName(prefix string, jsonName string) string {
+	name := strings.ToUpper(strings.NewReplacer("-", envSeparator, ".", envSeparator).Replace(jsonName))
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + envSeparator + name
+}
+
+// applyEnvStruct applies environment variables to all fields of structure
+// and reports whether any field has been changed
+func applyEnvStruct(structValue reflect.Value, prefix string, path string, sources Sources) (bool, error) {
+	changed := false
+
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if _, ok := embeddedStructType(field); ok {
+			// fields of embedded structures are named as fields of parent
+			fieldChanged, err := applyEnvValue(structValue.Field(i), prefix, path, sources)
+			if err != nil {
+				return changed, err
+			}
+			changed = changed || fieldChanged
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldChanged, err := applyEnvValue(structValue.Field(i), EnvName(prefix, name), joinPath(path, name), sources)
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || fieldChanged
+	}
+
+	return changed, nil
+}
+
+// applyEnvValue applies environment variable with name to value
+// and reports whether value has been changed
+func applyEnvValue(value reflect.Value, name string, path string, sources Sources) (bool, error) {
+	// nested structures are looked up by their own fields
+	if isNestedStruct(value.Type()) {
+		return applyEnvStruct(value, name, path, sources)
+	}
+	if value.Kind() == reflect.Pointer && isNestedStruct(value.Type().Elem()) {
+		nested := reflect.New(value.Type().Elem())
+		if !value.IsNil() {
+			nested.Elem().Set(value.Elem())
+		}
+
+		changed, err := applyEnvStruct(nested.Elem(), name, path, sources)
+		if err != nil || !changed {
+			return false, err
+		}
+
+		value.Set(nested)
+		return true, nil
+	}
+
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		return false, nil
+	}
+
+	if err := setFromString(value, raw); err != nil {
+		return false, fmt.Errorf("unable to set %s from environment: %v", name, err)
+	}
+	sources.set(path, LayerEnv)
+
+	return true, nil
+}
+
+// setFromString parses raw and stores result to value
+func setFromString(value reflect.Value, raw string) error {
+	if value.Kind() == reflect.Pointer {
+		elem := reflect.New(value.Type().Elem())
+		if err := setFromString(elem.Elem(), raw); err != nil {
+			return err
+		}
+		value.Set(elem)
+		return nil
+	}
+
+	if value.CanAddr() && value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		return setSliceFromString(value, raw)
+	case reflect.Map, reflect.Struct, reflect.Array, reflect.Interface:
+		return json.Unmarshal([]byte(raw), value.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+
+	return nil
+}
+
+// setSliceFromString parses raw as JSON array or comma separated list and stores result to value
+func setSliceFromString(value reflect.Value, raw string) error {
+	trimmed := strings.TrimSpace(raw)
+	if strings.HasPrefix(trimmed, "[") {
+		slice := reflect.New(value.Type())
+		if err := json.Unmarshal([]byte(trimmed), slice.Interface()); err != nil {
+			return err
+		}
+		value.Set(slice.Elem())
+		return nil
+	}
+
+	var parts []string
+	if trimmed != "" {
+		parts = strings.Split(raw, ",")
+	}
+
+	slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+	for i, part := range parts {
+		if err := setFromString(slice.Index(i), strings.TrimSpace(part)); err != nil {
+			return err
+		}
+	}
+	value.Set(slice)
+
+	return nil
+}
+
+// jsonFieldName returns field name from json tag
+// second value is false if field is skipped by json
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+
+	return name, true
+}
+
+// embeddedStructType returns type of structure which fields are promoted to parent like in encoding/json:
+// field is untagged anonymous structure or pointer to structure, second value is false otherwise
+func embeddedStructType(field reflect.StructField) (reflect.Type, bool) {
+	if !field.Anonymous {
+		return nil, false
+	}
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" {
+		return nil, false
+	}
+
+	t := field.Type
+	if t.Kind() == reflect.Pointer {
+		// encoding/json can't allocate unexported embedded pointers either
+		if !field.IsExported() {
+			return nil, false
+		}
+		t = t.Elem()
+	}
+
+	return t, isNestedStruct(t)
+}
+
+// isNestedStruct reports whether structures of type t should be walked field by field
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	return !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
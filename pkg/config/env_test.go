@@ -0,0 +1,88 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type envTestNested struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type envTestConfig struct {
+	ListenPort  int64          `json:"listen_port"`
+	PathsToLogs []string       `json:"paths_to_logs"`
+	Timeout     time.Duration  `json:"timeout"`
+	Debug       bool           `json:"debug"`
+	DB          envTestNested  `json:"db"`
+	Cache       *envTestNested `json:"cache"`
+	Skipped     string         `json:"-"`
+}
+
+func TestApplyEnv(t *testing.T) {
+	type args struct {
+		prefix string
+		env    map[string]string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    envTestConfig
+		wantErr bool
+	}{
+		{"empty", args{"", nil}, envTestConfig{ListenPort: 1}, false},
+		{"scalars", args{"", map[string]string{
+			"LISTEN_PORT": "10001",
+			"TIMEOUT":     "250ms",
+			"DEBUG":       "true",
+		}}, envTestConfig{ListenPort: 10001, Timeout: 250 * time.Millisecond, Debug: true}, false},
+		{"prefix", args{"app", map[string]string{
+			"LISTEN_PORT":     "10001",
+			"APP_LISTEN_PORT": "10002",
+		}}, envTestConfig{ListenPort: 10002}, false},
+		{"slice", args{"", map[string]string{
+			"LISTEN_PORT":   "1",
+			"PATHS_TO_LOGS": "stderr, logs/log",
+		}}, envTestConfig{ListenPort: 1, PathsToLogs: []string{"stderr", "logs/log"}}, false},
+		{"json slice", args{"", map[string]string{
+			"LISTEN_PORT":   "1",
+			"PATHS_TO_LOGS": `["a,b", "c"]`,
+		}}, envTestConfig{ListenPort: 1, PathsToLogs: []string{"a,b", "c"}}, false},
+		{"json slice with query", args{"", map[string]string{
+			"LISTEN_PORT":   "1",
+			"PATHS_TO_LOGS": `["stderr", "rotate:logs/log?max_size=100MiB&max_backups=10"]`,
+		}}, envTestConfig{ListenPort: 1, PathsToLogs: []string{"stderr", "rotate:logs/log?max_size=100MiB&max_backups=10"}}, false},
+		{"nested", args{"", map[string]string{
+			"DB_HOST":    "db",
+			"DB_PORT":    "5432",
+			"CACHE_PORT": "6379",
+		}}, envTestConfig{ListenPort: 1, DB: envTestNested{"db", 5432}, Cache: &envTestNested{Port: 6379}}, false},
+		{"skipped", args{"", map[string]string{"SKIPPED": "value"}}, envTestConfig{ListenPort: 1}, false},
+		{"bad int", args{"", map[string]string{"LISTEN_PORT": "port"}}, envTestConfig{}, true},
+		{"bad duration", args{"", map[string]string{"TIMEOUT": "5"}}, envTestConfig{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.args.env {
+				t.Setenv(key, value)
+			}
+
+			config := envTestConfig{ListenPort: 1}
+			err := ApplyEnv(tt.args.prefix, &config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ApplyEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(config, tt.want) {
+				t.Errorf("ApplyEnv() got = %+v, want %+v", config, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnvNotPointer(t *testing.T) {
+	if err := ApplyEnv("", envTestConfig{}); err == nil {
+		t.Errorf("ApplyEnv() expected error for non-pointer config")
+	}
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is a config file format
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// formatExtensions stores mapping from file extension to config file format
+var formatExtensions = map[string]Format{
+	".json": FormatJSON,
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".toml": FormatTOML,
+}
+
+// FormatFromFile returns config format by configFile extension
+func FormatFromFile(configFile string) (Format, error) {
+	const fn = "config.FormatFromFile"
+
+	format, ok := formatExtensions[strings.ToLower(filepath.Ext(configFile))]
+	if !ok {
+		return "", fmt.Errorf("%s: unknown config format of file %q", fn, configFile)
+	}
+
+	return format, nil
+}
+
+// toJSON converts data in format to JSON
+// documents in all formats are decoded with json struct tags, so the same config structure
+// can be used for all of them
+func toJSON(data []byte, format Format) ([]byte, error) {
+	var document map[string]interface{}
+
+	switch format {
+	case FormatJSON:
+		return data, nil
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if err := toml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown config format %q", format)
+	}
+
+	return json.Marshal(normalizeDocument(document))
+}
+
+// normalizeDocument converts decoded document to types which can be marshaled to JSON
+func normalizeDocument(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, elem := range typed {
+			typed[key] = normalizeDocument(elem)
+		}
+		return typed
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, elem := range typed {
+			result[fmt.Sprint(key)] = normalizeDocument(elem)
+		}
+		return result
+	case []interface{}:
+		for i, elem := range typed {
+			typed[i] = normalizeDocument(elem)
+		}
+		return typed
+	case []map[string]interface{}:
+		result := make([]interface{}, len(typed))
+		for i, elem := range typed {
+			result[i] = normalizeDocument(elem)
+		}
+		return result
+	default:
+		return value
+	}
+}
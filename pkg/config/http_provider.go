@@ -0,0 +1,173 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is used by HTTPProvider if PollInterval is not set
+	DefaultPollInterval = 30 * time.Second
+	// DefaultHTTPTimeout is used by HTTPProvider if Timeout is not set
+	DefaultHTTPTimeout = 10 * time.Second
+)
+
+// contentTypeFormats stores mapping from media type to config format
+var contentTypeFormats = map[string]Format{
+	"application/json":   FormatJSON,
+	"application/yaml":   FormatYAML,
+	"application/x-yaml": FormatYAML,
+	"text/yaml":          FormatYAML,
+	"text/x-yaml":        FormatYAML,
+	"application/toml":   FormatTOML,
+	"text/toml":          FormatTOML,
+}
+
+// HTTPProvider reads config document with GET request to URL
+// it can be used with key-value stores which serve raw values over HTTP,
+// e.g. Consul "/v1/kv/<key>?raw" or etcd gateway, and with local test servers
+type HTTPProvider struct {
+	// URL is a config document address
+	URL string
+	// Format is a format of config document, if it is empty, format is chosen by URL extension,
+	// then by Content-Type response header, JSON is used otherwise
+	Format Format
+	// Header is added to every request, it can be used for authorization tokens
+	Header http.Header
+	// Client sends requests, http.DefaultClient is used if it is nil
+	Client *http.Client
+	// PollInterval is an interval between config document checks in Watch, DefaultPollInterval is used if it is zero
+	PollInterval time.Duration
+	// Timeout limits every request, so stalled server doesn't block reloads, DefaultHTTPTimeout is used if it is zero
+	Timeout time.Duration
+}
+
+// NewHTTPProvider creates HTTPProvider of rawURL with default client, poll interval and timeout
+func NewHTTPProvider(rawURL string) *HTTPProvider {
+	return &HTTPProvider{URL: rawURL}
+}
+
+// Read implements Provider
+func (p *HTTPProvider) Read(ctx context.Context) ([]byte, Format, error) {
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	for key, values := range p.Header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected response status %q from %s", response.Status, p.URL)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read response from %s: %v", p.URL, err)
+	}
+
+	return data, p.format(response.Header.Get("Content-Type")), nil
+}
+
+// format returns format of config document with contentType
+func (p *HTTPProvider) format(contentType string) Format {
+	if p.Format != "" {
+		return p.Format
+	}
+
+	if u, err := url.Parse(p.URL); err == nil {
+		if format, ok := formatExtensions[strings.ToLower(path.Ext(u.Path))]; ok {
+			return format
+		}
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if format, ok := contentTypeFormats[mediaType]; ok {
+			return format
+		}
+	}
+
+	return FormatJSON
+}
+
+// Watch implements WatchingProvider
+// config document is requested every PollInterval and onChange is called when its content changes
+// or requests start or stop failing, so outage is reported to Watcher once instead of on every poll
+func (p *HTTPProvider) Watch(ctx context.Context, onChange func()) error {
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	lastHash, err := p.hash(ctx)
+	lastFailed := err != nil
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				hash, err := p.hash(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				failed := err != nil
+				if failed != lastFailed || (!failed && !bytes.Equal(hash, lastHash)) {
+					onChange()
+				}
+				if !failed {
+					lastHash = hash
+				}
+				lastFailed = failed
+			}
+		}
+	}()
+
+	return nil
+}
+
+// hash returns hash of current config document
+func (p *HTTPProvider) hash(ctx context.Context) ([]byte, error) {
+	data, _, err := p.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(data)
+	return hash[:], nil
+}
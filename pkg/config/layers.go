@@ -0,0 +1,432 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const defaultTag = "default"
+
+// Layer is a source of config values
+type Layer string
+
+// layers in order of precedence: every next layer overrides values set by previous ones
+const (
+	LayerDefault Layer = "default"
+	LayerFile    Layer = "file"
+	LayerEnvFile Layer = "env-file"
+	LayerEnv     Layer = "env"
+	LayerFlag    Layer = "flag"
+)
+
+// Sources stores layer which has set each config field
+// keys are field paths built from json names, e.g. db.host
+type Sources map[string]Layer
+
+// set marks path as set by layer
+func (s Sources) set(path string, layer Layer) {
+	if s != nil {
+		s[path] = layer
+	}
+}
+
+// String returns sources sorted by field path
+func (s Sources) String() string {
+	paths := make([]string, 0, len(s))
+	for path := range s {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	pairs := make([]string, 0, len(paths))
+	for _, path := range paths {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", path, s[path]))
+	}
+
+	return strings.Join(pairs, " ")
+}
+
+// Loader reads config from ordered layers, every next layer overrides values of previous ones:
+// default struct tags, ConfigFile, environment file, environment variables and command-line flags
+type Loader struct {
+	// ConfigFile is a base config file, it is skipped if empty
+	ConfigFile string
+	// Environment selects optional environment file next to ConfigFile:
+	// config.json with production environment is config.production.json
+	Environment string
+	// UseEnv enables environment variables layer, see ApplyEnv for details about variable names
+	UseEnv    bool
+	EnvPrefix string
+	// Flags enables command-line flags layer, see RegisterFlags
+	Flags *Flags
+	// Strict rejects keys in config files which don't match any field of config
+	Strict bool
+	// Secrets resolves secret references after all layers are loaded,
+	// if it is nil, DefaultSecretResolver is used
+	Secrets SecretResolver
+}
+
+// Load reads all layers to config, resolves secret references
+// and validates result with rules from validate struct tags
+// config must be reference to your config structure
+// returned Sources reports which layer has set each field
+func (l *Loader) Load(config interface{}) (Sources, error) {
+	const fn = "config.Loader.Load"
+
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: config must be non-nil pointer to structure, got %T", fn, config)
+	}
+
+	sources := Sources{}
+
+	if err := applyDefaults(value.Elem(), "", sources); err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if l.ConfigFile != "" {
+		if err := l.loadFile(l.ConfigFile, LayerFile, config, sources); err != nil {
+			return nil, fmt.Errorf("%s: %v", fn, err)
+		}
+
+		if l.Environment != "" {
+			err := l.loadFile(l.environmentFile(), LayerEnvFile, config, sources)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("%s: %v", fn, err)
+			}
+		}
+	}
+
+	if l.UseEnv {
+		if err := applyEnv(l.EnvPrefix, value.Elem(), sources); err != nil {
+			return nil, fmt.Errorf("%s: %v", fn, err)
+		}
+	}
+
+	if l.Flags != nil {
+		if err := l.Flags.apply(value.Elem(), sources); err != nil {
+			return nil, fmt.Errorf("%s: %v", fn, err)
+		}
+	}
+
+	if err := completeConfig(config, l.Secrets); err != nil {
+		return sources, fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return sources, nil
+}
+
+// LoadFunc returns LoadFunc which loads all layers with configFile as a base config file
+// Watcher created with it watches only configFile, use NewLoaderWatcher to watch environment file too
+func (l *Loader) LoadFunc() LoadFunc {
+	return func(configFile string, config interface{}) error {
+		loader := *l
+		loader.ConfigFile = configFile
+
+		_, err := loader.Load(config)
+		return err
+	}
+}
+
+// Files returns config files which are read by Load: ConfigFile and environment file
+// environment file is returned even if it doesn't exist, because it may be created later
+func (l *Loader) Files() []string {
+	if l.ConfigFile == "" {
+		return nil
+	}
+	if l.Environment == "" {
+		return []string{l.ConfigFile}
+	}
+
+	return []string{l.ConfigFile, l.environmentFile()}
+}
+
+// environmentFile returns name of environment file next to ConfigFile
+func (l *Loader) environmentFile() string {
+	return EnvironmentFile(l.ConfigFile, l.Environment)
+}
+
+// loadFile reads configFile over config and marks keys found in it as set by layer
+func (l *Loader) loadFile(configFile string, layer Layer, config interface{}, sources Sources) error {
+	format, err := FormatFromFile(configFile)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(configFile); err != nil {
+		return err
+	}
+
+	data, err := readJSON(configFile, format)
+	if err != nil {
+		return fmt.Errorf("%s: %v", configFile, err)
+	}
+
+	if err := decodeJSON(data, l.Strict, config); err != nil {
+		return fmt.Errorf("%s: %v", configFile, err)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("%s: %v", configFile, err)
+	}
+	markDocument(reflect.TypeOf(config).Elem(), document, "", layer, sources)
+
+	return nil
+}
+
+// EnvironmentFile returns name of environment file for configFile:
+// config.json with production environment is config.production.json
+func EnvironmentFile(configFile string, environment string) string {
+	ext := filepath.Ext(configFile)
+	return strings.TrimSuffix(configFile, ext) + "." + environment + ext
+}
+
+// markDocument marks all fields of structType which are present in document as set by layer
+func markDocument(structType reflect.Type, document map[string]interface{}, path string, layer Layer, sources Sources) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if embeddedType, ok := embeddedStructType(field); ok {
+			markDocument(embeddedType, document, path, layer, sources)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		value, ok := document[name]
+		if !ok {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		nested, isMap := value.(map[string]interface{})
+		if isMap && isNestedStruct(fieldType) {
+			markDocument(fieldType, nested, joinPath(path, name), layer, sources)
+			continue
+		}
+
+		sources.set(joinPath(path, name), layer)
+	}
+}
+
+// applyDefaults sets values from default struct tags
+// nested structures behind nil pointers are skipped
+func applyDefaults(structValue reflect.Value, path string, sources Sources) error {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if _, ok := embeddedStructType(field); ok {
+			embedded := reflect.Indirect(structValue.Field(i))
+			if !embedded.IsValid() {
+				continue
+			}
+			if err := applyDefaults(embedded, path, sources); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldValue := structValue.Field(i)
+		fieldPath := joinPath(path, name)
+
+		if defaultValue, ok := field.Tag.Lookup(defaultTag); ok {
+			if err := setFromString(fieldValue, defaultValue); err != nil {
+				return fmt.Errorf("invalid default value of %s: %v", fieldPath, err)
+			}
+			sources.set(fieldPath, LayerDefault)
+			continue
+		}
+
+		if fieldValue.Kind() == reflect.Pointer && !fieldValue.IsNil() {
+			fieldValue = fieldValue.Elem()
+		}
+		if isNestedStruct(fieldValue.Type()) {
+			if err := applyDefaults(fieldValue, fieldPath, sources); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Flags stores command-line flags which override config fields
+type Flags struct {
+	// values stores raw values of flags which have been set, keys are field paths
+	values map[string]string
+}
+
+// RegisterFlags registers flag for every field of config in flagSet
+// flag names are field paths built from json names: -listen_port, -db.host
+// config must be reference to your config structure, it is used only to get fields
+func RegisterFlags(flagSet *flag.FlagSet, config interface{}) (*Flags, error) {
+	const fn = "config.RegisterFlags"
+
+	configType := reflect.TypeOf(config)
+	if configType == nil || configType.Kind() != reflect.Pointer || configType.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: config must be pointer to structure, got %T", fn, config)
+	}
+
+	flags := &Flags{values: map[string]string{}}
+	flags.register(flagSet, configType.Elem(), "")
+
+	return flags, nil
+}
+
+// register registers flags for all fields of structType
+func (f *Flags) register(flagSet *flag.FlagSet, structType reflect.Type, path string) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if embeddedType, ok := embeddedStructType(field); ok {
+			f.register(flagSet, embeddedType, path)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldPath := joinPath(path, name)
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer && isNestedStruct(fieldType.Elem()) {
+			fieldType = fieldType.Elem()
+		}
+		if isNestedStruct(fieldType) {
+			f.register(flagSet, fieldType, fieldPath)
+			continue
+		}
+
+		flagSet.Func(fieldPath, fmt.Sprintf("overrides %s config value (%s)", fieldPath, field.Type), func(raw string) error {
+			// check value right away to report error together with other flag errors
+			if err := setFromString(reflect.New(field.Type).Elem(), raw); err != nil {
+				return err
+			}
+
+			f.values[fieldPath] = raw
+			return nil
+		})
+	}
+}
+
+// apply sets values of all flags which have been set to config structure
+func (f *Flags) apply(structValue reflect.Value, sources Sources) error {
+	paths := make([]string, 0, len(f.values))
+	for path := range f.values {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fieldValue, err := fieldByPath(structValue, path)
+		if err != nil {
+			return err
+		}
+
+		if err := setFromString(fieldValue, f.values[path]); err != nil {
+			return fmt.Errorf("unable to set %s from flag: %v", path, err)
+		}
+		sources.set(path, LayerFlag)
+	}
+
+	return nil
+}
+
+// fieldByPath returns field of structure by path built from json names
+// nil pointers to nested structures are allocated on the way
+func fieldByPath(structValue reflect.Value, path string) (reflect.Value, error) {
+	name, rest, nested := strings.Cut(path, ".")
+
+	// fields of embedded structures are looked up after own fields which shadow them
+	var embedded []int
+
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if _, ok := embeddedStructType(field); ok {
+			embedded = append(embedded, i)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if fieldName, ok := jsonFieldName(field); !ok || fieldName != name {
+			continue
+		}
+
+		fieldValue := structValue.Field(i)
+		if !nested {
+			return fieldValue, nil
+		}
+
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		if fieldValue.Kind() != reflect.Struct {
+			break
+		}
+
+		return fieldByPath(fieldValue, rest)
+	}
+
+	for _, i := range embedded {
+		if fieldValue, err := embeddedFieldByPath(structValue.Field(i), path); err == nil {
+			return fieldValue, nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("unknown config field %q", path)
+}
+
+// embeddedFieldByPath returns field of embedded structure by path
+// nil pointer to embedded structure is allocated only if field is found
+func embeddedFieldByPath(embedded reflect.Value, path string) (reflect.Value, error) {
+	if embedded.Kind() != reflect.Pointer {
+		return fieldByPath(embedded, path)
+	}
+
+	allocated := embedded
+	if embedded.IsNil() {
+		allocated = reflect.New(embedded.Type().Elem())
+	}
+
+	fieldValue, err := fieldByPath(allocated.Elem(), path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	embedded.Set(allocated)
+
+	return fieldValue, nil
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type layersTestDB struct {
+	Host string `json:"host" default:"localhost"`
+	Port int    `json:"port" default:"5432"`
+	User string `json:"user" default:"postgres"`
+}
+
+type layersTestConfig struct {
+	ListenHost  string       `json:"listen_host"`
+	ListenPort  int64        `json:"listen_port" default:"8080" validate:"min=1"`
+	PathsToLogs []string     `json:"paths_to_logs" default:"stderr"`
+	LogLevel    string       `json:"log_level" default:"info"`
+	DB          layersTestDB `json:"db"`
+}
+
+func TestLoaderLoad(t *testing.T) {
+	t.Setenv("APP_DB_USER", "service")
+	t.Setenv("APP_LOG_LEVEL", "warn")
+
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := RegisterFlags(flagSet, &layersTestConfig{})
+	if err != nil {
+		t.Fatalf("RegisterFlags() error = %v", err)
+	}
+	if err := flagSet.Parse([]string{"-log_level", "debug", "-db.host", "replica"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	loader := Loader{
+		ConfigFile:  "test_files/layers.json",
+		Environment: "production",
+		UseEnv:      true,
+		EnvPrefix:   "APP",
+		Flags:       flags,
+	}
+
+	config := layersTestConfig{}
+	sources, err := loader.Load(&config)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	wantConfig := layersTestConfig{
+		ListenHost:  "localhost",
+		ListenPort:  10002,
+		PathsToLogs: []string{"stderr"},
+		LogLevel:    "debug",
+		DB:          layersTestDB{Host: "replica", Port: 6432, User: "service"},
+	}
+	if !reflect.DeepEqual(config, wantConfig) {
+		t.Errorf("Load() config = %+v, want %+v", config, wantConfig)
+	}
+
+	wantSources := Sources{
+		"listen_host":   LayerFile,
+		"listen_port":   LayerEnvFile,
+		"paths_to_logs": LayerDefault,
+		"log_level":     LayerFlag,
+		"db.host":       LayerFlag,
+		"db.port":       LayerEnvFile,
+		"db.user":       LayerEnv,
+	}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("Load() sources = %v, want %v", sources, wantSources)
+	}
+}
+
+func TestLoaderLoadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		loader Loader
+	}{
+		{"missing base file", Loader{ConfigFile: "test_files/missing.json"}},
+		{"strict", Loader{ConfigFile: "test_files/config2.json", Strict: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.loader.Load(&testConfig{}); err == nil {
+				t.Errorf("Load() expected error")
+			}
+		})
+	}
+
+	// missing environment file is skipped
+	loader := Loader{ConfigFile: "test_files/config1.json", Environment: "staging"}
+	if _, err := loader.Load(&testConfig{}); err != nil {
+		t.Errorf("Load() error = %v", err)
+	}
+}
+
+func TestRegisterFlagsInvalidValue(t *testing.T) {
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	if _, err := RegisterFlags(flagSet, &layersTestConfig{}); err != nil {
+		t.Fatalf("RegisterFlags() error = %v", err)
+	}
+	if err := flagSet.Parse([]string{"-listen_port", "port"}); err == nil {
+		t.Errorf("Parse() expected error for invalid value")
+	}
+}
+
+type layersTestServer struct {
+	ListenHost string `json:"listen_host" default:"localhost"`
+	ListenPort int64  `json:"listen_port" validate:"min=1"`
+}
+
+// LayersTestLogging is exported because encoding/json walks only exported embedded pointers
+type LayersTestLogging struct {
+	LogLevel string `json:"log_level" default:"info"`
+}
+
+type layersTestEmbeddedConfig struct {
+	layersTestServer
+	*LayersTestLogging `json:",omitempty"`
+	DB                 layersTestDB `json:"db"`
+}
+
+func TestLoaderLoadEmbedded(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"listen_port": 10001, "db": {"port": 6432}}`), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	t.Setenv("APP_LISTEN_HOST", "0.0.0.0")
+
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := RegisterFlags(flagSet, &layersTestEmbeddedConfig{})
+	if err != nil {
+		t.Fatalf("RegisterFlags() error = %v", err)
+	}
+	if err := flagSet.Parse([]string{"-log_level", "debug"}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	loader := Loader{ConfigFile: configFile, UseEnv: true, EnvPrefix: "APP", Flags: flags}
+
+	config := layersTestEmbeddedConfig{}
+	sources, err := loader.Load(&config)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	wantConfig := layersTestEmbeddedConfig{
+		layersTestServer:  layersTestServer{ListenHost: "0.0.0.0", ListenPort: 10001},
+		LayersTestLogging: &LayersTestLogging{LogLevel: "debug"},
+		DB:                layersTestDB{Host: "localhost", Port: 6432, User: "postgres"},
+	}
+	if !reflect.DeepEqual(config, wantConfig) {
+		t.Errorf("Load() config = %+v, want %+v", config, wantConfig)
+	}
+
+	wantSources := Sources{
+		"listen_host": LayerEnv,
+		"listen_port": LayerFile,
+		"log_level":   LayerFlag,
+		"db.host":     LayerDefault,
+		"db.port":     LayerFile,
+		"db.user":     LayerDefault,
+	}
+	if !reflect.DeepEqual(sources, wantSources) {
+		t.Errorf("Load() sources = %v, want %v", sources, wantSources)
+	}
+
+	config.ListenPort = 0
+	var errs ValidationErrors
+	if !errors.As(Validate(&config), &errs) || len(errs) != 1 || errs[0].Path != "listen_port" {
+		t.Errorf("Validate() errors = %v, want listen_port error", errs)
+	}
+
+	if described := Describe(&config); described["listen_host"] != "0.0.0.0" || described["log_level"] != "debug" {
+		t.Errorf("Describe() = %v, want flattened embedded fields", described)
+	}
+
+	schema, err := NewJSONSchema(&config, "")
+	if err != nil {
+		t.Fatalf("NewJSONSchema() error = %v", err)
+	}
+	for _, name := range []string{"listen_host", "listen_port", "log_level", "db"} {
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("NewJSONSchema() properties = %v, want %s", schema.Properties, name)
+		}
+	}
+}
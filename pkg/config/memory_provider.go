@@ -0,0 +1,69 @@
+package config
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryProvider stores config document in memory
+// it is useful in tests and for configs built by application itself
+type MemoryProvider struct {
+	mu       sync.Mutex
+	data     []byte
+	format   Format
+	watchers map[*func()]struct{}
+}
+
+// NewMemoryProvider creates MemoryProvider with data in format
+func NewMemoryProvider(data []byte, format Format) *MemoryProvider {
+	return &MemoryProvider{
+		data:   data,
+		format: format,
+	}
+}
+
+// Read implements Provider
+func (p *MemoryProvider) Read(_ context.Context) ([]byte, Format, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]byte(nil), p.data...), p.format, nil
+}
+
+// Set replaces config document and notifies watchers
+func (p *MemoryProvider) Set(data []byte) {
+	p.mu.Lock()
+	p.data = append([]byte(nil), data...)
+	watchers := make([]func(), 0, len(p.watchers))
+	for onChange := range p.watchers {
+		watchers = append(watchers, *onChange)
+	}
+	p.mu.Unlock()
+
+	for _, onChange := range watchers {
+		onChange()
+	}
+}
+
+// Watch implements WatchingProvider
+// onChange is called by Set until ctx is done
+func (p *MemoryProvider) Watch(ctx context.Context, onChange func()) error {
+	key := &onChange
+
+	p.mu.Lock()
+	if p.watchers == nil {
+		p.watchers = make(map[*func()]struct{})
+	}
+	p.watchers[key] = struct{}{}
+	p.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		p.mu.Lock()
+		delete(p.watchers, key)
+		p.mu.Unlock()
+	}()
+
+	return nil
+}
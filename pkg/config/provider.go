@@ -0,0 +1,150 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// kubernetesDataDir is a symlink which is atomically replaced when kubernetes updates mounted config map
+const kubernetesDataDir = "..data"
+
+// Provider is a source of config document: file, key-value store or anything else
+type Provider interface {
+	// Read returns config document and its format
+	Read(ctx context.Context) ([]byte, Format, error)
+}
+
+// WatchingProvider is a Provider which can notify about config document changes
+type WatchingProvider interface {
+	Provider
+	// Watch starts watching and calls onChange from another goroutine every time config document
+	// may have changed until ctx is done
+	// it returns error if watching is impossible
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// NewConfigFromProvider reads config from provider, resolves secret references with DefaultSecretResolver
+// and validates it with rules from validate struct tags
+// config must be reference to your config structure
+func NewConfigFromProvider(ctx context.Context, provider Provider, config interface{}) error {
+	const fn = "config.NewConfigFromProvider"
+
+	if err := readProvider(ctx, provider, false, config); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	if err := completeConfig(config, nil); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+
+	return nil
+}
+
+// readProvider reads and decodes config document from provider to config
+// if strict is true, unknown keys are treated as error
+func readProvider(ctx context.Context, provider Provider, strict bool, config interface{}) error {
+	data, err := readProviderJSON(ctx, provider)
+	if err != nil {
+		return err
+	}
+
+	return decodeJSON(data, strict, config)
+}
+
+// readProviderJSON reads config document from provider and converts it to JSON
+func readProviderJSON(ctx context.Context, provider Provider) ([]byte, error) {
+	document, format, err := provider.Read(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config: %v", err)
+	}
+
+	data, err := toJSON(document, format)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode config: %v", err)
+	}
+
+	return data, nil
+}
+
+// FileProvider reads config from file
+type FileProvider struct {
+	// Path is a path to config file
+	Path string
+	// Format is a format of config file, if it is empty, format is chosen by file extension
+	Format Format
+}
+
+// NewFileProvider creates FileProvider with format chosen by file extension
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{Path: path}
+}
+
+// Read implements Provider
+func (p *FileProvider) Read(_ context.Context) ([]byte, Format, error) {
+	format := p.Format
+	if format == "" {
+		var err error
+		if format, err = FormatFromFile(p.Path); err != nil {
+			return nil, "", err
+		}
+	}
+
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, format, nil
+}
+
+// Watch implements WatchingProvider
+// directory of file is watched instead of file itself, because editors and kubernetes replace files
+// instead of writing them
+func (p *FileProvider) Watch(ctx context.Context, onChange func()) error {
+	fileWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("unable to create file watcher: %v", err)
+	}
+
+	if err := fileWatcher.Add(filepath.Dir(p.Path)); err != nil {
+		_ = fileWatcher.Close()
+		return fmt.Errorf("unable to watch config file: %v", err)
+	}
+
+	go p.watch(ctx, fileWatcher, onChange)
+
+	return nil
+}
+
+// watch calls onChange on events of config file until ctx is done
+func (p *FileProvider) watch(ctx context.Context, fileWatcher *fsnotify.Watcher, onChange func()) {
+	defer func() {
+		_ = fileWatcher.Close()
+	}()
+
+	configFile := filepath.Clean(p.Path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fileWatcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			if name == configFile || filepath.Base(name) == kubernetesDataDir {
+				onChange()
+			}
+		case _, ok := <-fileWatcher.Errors:
+			if !ok {
+				return
+			}
+			// some events may be lost, so file may have changed
+			onChange()
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewConfigFromProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		want     watcherTestConfig
+		wantErr  bool
+	}{
+		{
+			name:     "file",
+			provider: NewFileProvider("test_files/config1.yaml"),
+			want:     watcherTestConfig{ListenPort: 10001, LogLevel: "debug"},
+		},
+		{
+			name:     "memory yaml",
+			provider: NewMemoryProvider([]byte("listen_port: 10001\nlog_level: debug\n"), FormatYAML),
+			want:     watcherTestConfig{ListenPort: 10001, LogLevel: "debug"},
+		},
+		{
+			name:     "memory toml",
+			provider: NewMemoryProvider([]byte("listen_port = 10002\n"), FormatTOML),
+			want:     watcherTestConfig{ListenPort: 10002},
+		},
+		{
+			name:     "invalid config",
+			provider: NewMemoryProvider([]byte(`{"listen_port": 0}`), FormatJSON),
+			wantErr:  true,
+		},
+		{
+			name:     "unknown format",
+			provider: NewMemoryProvider([]byte(`listen_port: 1`), Format("ini")),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got watcherTestConfig
+			err := NewConfigFromProvider(context.Background(), tt.provider, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewConfigFromProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("NewConfigFromProvider() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPProviderRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/config.yaml":
+			_, _ = w.Write([]byte("listen_port: 10001\n"))
+		case "/kv/typed":
+			w.Header().Set("Content-Type", "application/toml; charset=utf-8")
+			_, _ = w.Write([]byte("listen_port = 10002\n"))
+		case "/kv/raw":
+			_, _ = w.Write([]byte(`{"listen_port": 10003}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	header := http.Header{"X-Token": []string{"secret"}}
+	tests := []struct {
+		name     string
+		provider *HTTPProvider
+		want     int64
+		wantErr  bool
+	}{
+		{
+			name:     "format by extension",
+			provider: &HTTPProvider{URL: server.URL + "/config.yaml", Header: header},
+			want:     10001,
+		},
+		{
+			name:     "format by content type",
+			provider: &HTTPProvider{URL: server.URL + "/kv/typed", Header: header},
+			want:     10002,
+		},
+		{
+			name:     "default format",
+			provider: &HTTPProvider{URL: server.URL + "/kv/raw", Header: header},
+			want:     10003,
+		},
+		{
+			name:     "not found",
+			provider: &HTTPProvider{URL: server.URL + "/kv/missing", Header: header},
+			wantErr:  true,
+		},
+		{
+			name:     "forbidden",
+			provider: NewHTTPProvider(server.URL + "/kv/raw"),
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got watcherTestConfig
+			err := NewConfigFromProvider(context.Background(), tt.provider, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewConfigFromProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.ListenPort != tt.want {
+				t.Errorf("NewConfigFromProvider() listen_port = %d, want %d", got.ListenPort, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderWatcher(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		document = `{"listen_port": 10001}`
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		_, _ = w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeTestFile(t, configFile, `{"listen_port": 10001}`)
+
+	memoryProvider := NewMemoryProvider([]byte(`{"listen_port": 10001}`), FormatJSON)
+	tests := []struct {
+		name     string
+		provider Provider
+		update   func()
+	}{
+		{
+			name:     "memory",
+			provider: memoryProvider,
+			update: func() {
+				memoryProvider.Set([]byte(`{"listen_port": 10002}`))
+			},
+		},
+		{
+			name:     "http",
+			provider: &HTTPProvider{URL: server.URL, PollInterval: 10 * time.Millisecond},
+			update: func() {
+				mu.Lock()
+				defer mu.Unlock()
+				document = `{"listen_port": 10002}`
+			},
+		},
+		{
+			name:     "file",
+			provider: NewFileProvider(configFile),
+			update: func() {
+				writeTestFile(t, configFile, `{"listen_port": 10002}`)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watcher, err := NewProviderWatcher[watcherTestConfig](tt.provider)
+			if err != nil {
+				t.Fatalf("NewProviderWatcher() error = %v", err)
+			}
+			if got := watcher.Config(); got.ListenPort != 10001 {
+				t.Fatalf("Config() = %+v", got)
+			}
+
+			reloaded := make(chan *watcherTestConfig, 1)
+			watcher.Subscribe(func(_, newConfig *watcherTestConfig) {
+				reloaded <- newConfig
+			})
+
+			if err := watcher.Start(nil); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			defer watcher.Close()
+
+			tt.update()
+
+			select {
+			case newConfig := <-reloaded:
+				if newConfig.ListenPort != 10002 {
+					t.Errorf("reloaded config = %+v", newConfig)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("config has not been reloaded")
+			}
+		})
+	}
+}
+
+func TestHTTPProviderWatchFailures(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"listen_port": 10001}`))
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var changes atomic.Int64
+	provider := &HTTPProvider{URL: server.URL, PollInterval: 5 * time.Millisecond}
+	if err := provider.Watch(ctx, func() { changes.Add(1) }); err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// waitChanges waits for several polls and checks number of reported changes
+	waitChanges := func(want int64) {
+		t.Helper()
+		time.Sleep(100 * time.Millisecond)
+		if got := changes.Load(); got != want {
+			t.Fatalf("onChange called %d times, want %d", got, want)
+		}
+	}
+
+	// persistently failing endpoint is not a change
+	waitChanges(0)
+
+	// recovery and next failure are reported once each
+	failing.Store(false)
+	waitChanges(1)
+	failing.Store(true)
+	waitChanges(2)
+}
+
+func TestHTTPProviderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	provider := &HTTPProvider{URL: server.URL, Timeout: 50 * time.Millisecond}
+	if _, _, err := provider.Read(context.Background()); err == nil {
+		t.Errorf("Read() expected error for stalled server")
+	}
+}
+
+func TestProviderWatcherCloseCancelsReload(t *testing.T) {
+	var stalled atomic.Bool
+	requested := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if stalled.Load() {
+			requested <- struct{}{}
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`{"listen_port": 10001}`))
+	}))
+	defer server.Close()
+
+	watcher, err := NewProviderWatcher[watcherTestConfig](&HTTPProvider{URL: server.URL, Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("NewProviderWatcher() error = %v", err)
+	}
+
+	stalled.Store(true)
+	reloaded := make(chan error, 1)
+	go func() {
+		reloaded <- watcher.Reload()
+	}()
+	<-requested
+
+	// stalled reload must not block subscribers
+	subscribed := make(chan struct{})
+	go func() {
+		watcher.Subscribe(func(_, _ *watcherTestConfig) {})
+		close(subscribed)
+	}()
+	select {
+	case <-subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Subscribe() is blocked by reload")
+	}
+
+	watcher.Close()
+	select {
+	case err := <-reloaded:
+		if err == nil {
+			t.Errorf("Reload() expected error after Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Reload() has not been cancelled by Close")
+	}
+	if got := watcher.Config(); got.ListenPort != 10001 {
+		t.Errorf("Config() after cancelled reload = %+v", got)
+	}
+}
@@ -0,0 +1,473 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	descriptionTag = "description"
+
+	jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+)
+
+// JSONSchema is a JSON Schema document
+// only keywords needed to describe config structures are supported
+type JSONSchema struct {
+	Schema      string `json:"$schema,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Type is a type name or a list of type names
+	Type    interface{}   `json:"type,omitempty"`
+	Default interface{}   `json:"default,omitempty"`
+	Enum    []interface{} `json:"enum,omitempty"`
+
+	Minimum   *float64 `json:"minimum,omitempty"`
+	Maximum   *float64 `json:"maximum,omitempty"`
+	MinLength *int     `json:"minLength,omitempty"`
+	MaxLength *int     `json:"maxLength,omitempty"`
+	MinItems  *int     `json:"minItems,omitempty"`
+	MaxItems  *int     `json:"maxItems,omitempty"`
+
+	WriteOnly bool `json:"writeOnly,omitempty"`
+
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+}
+
+// SchemaField describes one config field in reference documentation
+type SchemaField struct {
+	// Path is a field path built from json names, e.g. db.host
+	Path string
+	// Type is a JSON Schema type of field
+	Type string
+	// Default is a value from default struct tag
+	Default string
+	// Rules is a value from validate struct tag
+	Rules string
+	// Required reports whether field has required validation rule
+	Required bool
+	// Secret reports whether field stores secret value
+	Secret bool
+	// Env is a name of environment variable which overrides field without prefix
+	Env string
+	// Description is a value from description struct tag
+	Description string
+}
+
+// NewJSONSchema returns JSON Schema of config structure built from json, default, validate
+// and description struct tags
+// config must be structure or reference to it
+func NewJSONSchema(config interface{}, title string) (*JSONSchema, error) {
+	const fn = "config.NewJSONSchema"
+
+	configType, err := structType(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+
+	schema, err := structSchema(configType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+	schema.Schema = jsonSchemaDraft
+	schema.Title = title
+
+	return schema, nil
+}
+
+// MarshalJSONSchema returns indented JSON Schema document of config structure
+func MarshalJSONSchema(config interface{}, title string) ([]byte, error) {
+	schema, err := NewJSONSchema(config, title)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// SchemaFields returns flat list of config fields in their order,
+// nested structures are replaced by their fields
+// config must be structure or reference to it
+func SchemaFields(config interface{}) ([]SchemaField, error) {
+	const fn = "config.SchemaFields"
+
+	configType, err := structType(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+
+	var fields []SchemaField
+	collectSchemaFields(configType, "", "", &fields)
+
+	return fields, nil
+}
+
+// MarkdownReference returns reference table of config fields in Markdown format
+func MarkdownReference(config interface{}, title string) (string, error) {
+	fields, err := SchemaFields(config)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if title != "" {
+		fmt.Fprintf(&builder, "# %s\n\n", title)
+	}
+
+	builder.WriteString("| Field | Type | Required | Default | Validation | Environment | Description |\n")
+	builder.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, field := range fields {
+		required := ""
+		if field.Required {
+			required = "yes"
+		}
+
+		description := field.Description
+		if field.Secret {
+			description = strings.TrimSpace("secret, masked in logs. " + description)
+		}
+
+		fmt.Fprintf(&builder, "| `%s` | %s | %s | %s | %s | `%s` | %s |\n",
+			field.Path, field.Type, required, markdownCode(field.Default), markdownCode(field.Rules), field.Env,
+			markdownEscape(description))
+	}
+
+	return builder.String(), nil
+}
+
+// structType returns structure type of config
+func structType(config interface{}) (reflect.Type, error) {
+	configType := reflect.TypeOf(config)
+	for configType != nil && configType.Kind() == reflect.Pointer {
+		configType = configType.Elem()
+	}
+	if configType == nil || configType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config must be structure or reference to it, got %T", config)
+	}
+
+	return configType, nil
+}
+
+// structSchema returns schema of structure type
+func structSchema(t reflect.Type) (*JSONSchema, error) {
+	schema := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: false,
+	}
+
+	// properties of embedded structures are added after own ones which shadow them
+	var embedded []*JSONSchema
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if embeddedType, ok := embeddedStructType(field); ok {
+			embeddedSchema, err := structSchema(embeddedType)
+			if err != nil {
+				return nil, err
+			}
+			embedded = append(embedded, embeddedSchema)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldSchema, err := fieldSchema(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		schema.Properties[name] = fieldSchema
+
+		if hasRule(field.Tag.Get(validateTag), RuleRequired) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	for _, embeddedSchema := range embedded {
+		for name, property := range embeddedSchema.Properties {
+			if _, ok := schema.Properties[name]; !ok {
+				schema.Properties[name] = property
+			}
+		}
+		for _, name := range embeddedSchema.Required {
+			if schema.Properties[name] == embeddedSchema.Properties[name] {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+
+	return schema, nil
+}
+
+// fieldSchema returns schema of structure field with keywords from its tags
+func fieldSchema(field reflect.StructField) (*JSONSchema, error) {
+	schema, err := typeSchema(field.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if description := field.Tag.Get(descriptionTag); description != "" {
+		schema.Description = strings.TrimSpace(description + " " + schema.Description)
+	}
+	schema.WriteOnly = IsSecretField(field)
+
+	if defaultValue, ok := field.Tag.Lookup(defaultTag); ok {
+		value := reflect.New(field.Type).Elem()
+		if err := setFromString(value, defaultValue); err != nil {
+			return nil, fmt.Errorf("invalid default value: %v", err)
+		}
+		schema.Default = value.Interface()
+	}
+
+	if rules := field.Tag.Get(validateTag); rules != "" {
+		ruleType := field.Type
+		if ruleType.Kind() == reflect.Pointer {
+			ruleType = ruleType.Elem()
+		}
+		if err := applyRules(schema, ruleType, rules); err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+// typeSchema returns schema of type without keywords from tags
+func typeSchema(t reflect.Type) (*JSONSchema, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		return &JSONSchema{Type: "integer", Description: "(nanoseconds)"}, nil
+	case t == configDurationType:
+		return &JSONSchema{Type: "string", Description: "(duration, e.g. 250ms or 5s)"}, nil
+	case t == byteSizeType:
+		// numbers are read as size in bytes
+		return &JSONSchema{Type: []string{"string", "integer"}, Description: "(byte size, e.g. 512KiB or 10MiB)"}, nil
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return &JSONSchema{Type: "string"}, nil
+	case isNestedStruct(t):
+		return structSchema(t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &JSONSchema{Type: "integer", Minimum: &minimum}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+// applyRules adds JSON Schema keywords for validation rules
+// rules which can't be expressed in JSON Schema are added to description
+func applyRules(schema *JSONSchema, t reflect.Type, rules string) error {
+	var notes []string
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case RuleMin, RuleMax:
+			if isDurationType(t) || t == byteSizeType {
+				notes = append(notes, rule)
+				continue
+			}
+			bound, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s parameter %q: %v", name, param, err)
+			}
+			setBound(schema, name, bound)
+		case RuleOneOf:
+			for _, allowed := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(t, allowed))
+			}
+			// empty value skips other rules
+			if hasRule(rules, RuleOmitEmpty) {
+				schema.Enum = append(schema.Enum, reflect.Zero(t).Interface())
+			}
+		case RuleHostPort:
+			notes = append(notes, "host:port")
+		case RuleFileExists:
+			notes = append(notes, "path to existing file")
+		}
+	}
+
+	if len(notes) > 0 {
+		schema.Description = strings.TrimSpace(schema.Description + " (" + strings.Join(notes, ", ") + ")")
+	}
+
+	return nil
+}
+
+// setBound sets min or max keyword matching schema type
+func setBound(schema *JSONSchema, name string, bound float64) {
+	length := int(bound)
+
+	switch schema.Type {
+	case "string":
+		if name == RuleMin {
+			schema.MinLength = &length
+		} else {
+			schema.MaxLength = &length
+		}
+	case "array":
+		if name == RuleMin {
+			schema.MinItems = &length
+		} else {
+			schema.MaxItems = &length
+		}
+	default:
+		if name == RuleMin {
+			schema.Minimum = &bound
+		} else {
+			schema.Maximum = &bound
+		}
+	}
+}
+
+// enumValue converts oneof parameter to value of type t
+func enumValue(t reflect.Type, raw string) interface{} {
+	value := reflect.New(t).Elem()
+	if err := setFromString(value, raw); err != nil {
+		return raw
+	}
+
+	return value.Interface()
+}
+
+// collectSchemaFields appends all fields of structure type to fields
+func collectSchemaFields(t reflect.Type, path string, envPrefix string, fields *[]SchemaField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if embeddedType, ok := embeddedStructType(field); ok {
+			collectSchemaFields(embeddedType, path, envPrefix, fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if isNestedStruct(fieldType) {
+			collectSchemaFields(fieldType, joinPath(path, name), EnvName(envPrefix, name), fields)
+			continue
+		}
+
+		rules := field.Tag.Get(validateTag)
+		*fields = append(*fields, SchemaField{
+			Path:        joinPath(path, name),
+			Type:        typeName(fieldType),
+			Default:     field.Tag.Get(defaultTag),
+			Rules:       rules,
+			Required:    hasRule(rules, RuleRequired),
+			Secret:      IsSecretField(field),
+			Env:         EnvName(envPrefix, name),
+			Description: field.Tag.Get(descriptionTag),
+		})
+	}
+}
+
+// typeName returns human readable JSON type name
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		return "duration (ns)"
+	case t == configDurationType:
+		return "duration"
+	case t == byteSizeType:
+		return "byte size"
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return "array of " + typeName(t.Elem())
+	case t.Kind() == reflect.Map:
+		return "map of " + typeName(t.Elem())
+	}
+
+	schema, err := typeSchema(t)
+	if err != nil {
+		return t.String()
+	}
+
+	name, ok := schema.Type.(string)
+	if !ok || name == "" {
+		return t.String()
+	}
+
+	return name
+}
+
+// hasRule reports whether rules contain rule without parameter
+func hasRule(rules string, rule string) bool {
+	for _, r := range strings.Split(rules, ",") {
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markdownCode formats value as inline code if it isn't empty
+func markdownCode(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return "`" + markdownEscape(value) + "`"
+}
+
+// markdownEscape escapes characters which break Markdown tables
+func markdownEscape(value string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(value)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type schemaTestConfig struct {
+	ListenHost  string   `json:"listen_host" default:"localhost" description:"host of http server"`
+	ListenPort  int64    `json:"listen_port" default:"10001" validate:"required,min=1,max=65535"`
+	LogEnv      string   `json:"log_env" validate:"oneof=development production"`
+	LogFormat   string   `json:"log_format" validate:"omitempty,oneof=json console"`
+	MaxSize     ByteSize `json:"max_size"`
+	PathsToLogs []string `json:"paths_to_logs" validate:"max=3"`
+	DBPassword  Secret   `json:"db_password"`
+	DB          struct {
+		Address string `json:"address" validate:"hostport"`
+	} `json:"db"`
+}
+
+func TestMarshalJSONSchema(t *testing.T) {
+	data, err := MarshalJSONSchema(&schemaTestConfig{}, "test")
+	if err != nil {
+		t.Fatalf("MarshalJSONSchema() error = %v", err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("MarshalJSONSchema() returned invalid JSON: %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	listenPort := properties["listen_port"].(map[string]interface{})
+	if listenPort["type"] != "integer" || listenPort["minimum"] != 1.0 || listenPort["maximum"] != 65535.0 || listenPort["default"] != 10001.0 {
+		t.Errorf("listen_port schema = %v", listenPort)
+	}
+	if required := schema["required"].([]interface{}); len(required) != 1 || required[0] != "listen_port" {
+		t.Errorf("required = %v", required)
+	}
+	if enum := properties["log_env"].(map[string]interface{})["enum"].([]interface{}); len(enum) != 2 {
+		t.Errorf("log_env enum = %v", enum)
+	}
+	if enum := properties["log_format"].(map[string]interface{})["enum"].([]interface{}); len(enum) != 3 || enum[2] != "" {
+		t.Errorf("log_format enum = %v, want empty value allowed", enum)
+	}
+	if maxSize := properties["max_size"].(map[string]interface{})["type"].([]interface{}); len(maxSize) != 2 || maxSize[0] != "string" || maxSize[1] != "integer" {
+		t.Errorf("max_size type = %v", maxSize)
+	}
+	if maxItems := properties["paths_to_logs"].(map[string]interface{})["maxItems"]; maxItems != 3.0 {
+		t.Errorf("paths_to_logs maxItems = %v", maxItems)
+	}
+	if writeOnly := properties["db_password"].(map[string]interface{})["writeOnly"]; writeOnly != true {
+		t.Errorf("db_password writeOnly = %v", writeOnly)
+	}
+	db := properties["db"].(map[string]interface{})
+	if _, ok := db["properties"].(map[string]interface{})["address"]; !ok || db["additionalProperties"] != false {
+		t.Errorf("db schema = %v", db)
+	}
+}
+
+func TestMarkdownReference(t *testing.T) {
+	reference, err := MarkdownReference(schemaTestConfig{}, "Config")
+	if err != nil {
+		t.Fatalf("MarkdownReference() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"# Config",
+		"| `listen_host` | string |  | `localhost` |  | `LISTEN_HOST` | host of http server |",
+		"| `listen_port` | integer | yes | `10001` | `required,min=1,max=65535` | `LISTEN_PORT` |  |",
+		"| `paths_to_logs` | array of string |",
+		"| `db.address` | string |  |  | `hostport` | `DB_ADDRESS` |  |",
+		"secret, masked in logs",
+	} {
+		if !strings.Contains(reference, want) {
+			t.Errorf("MarkdownReference() doesn't contain %q:\n%s", want, reference)
+		}
+	}
+}
+
+func TestSchemaNotStruct(t *testing.T) {
+	if _, err := NewJSONSchema(42, ""); err == nil {
+		t.Errorf("NewJSONSchema() expected error for non-structure")
+	}
+}
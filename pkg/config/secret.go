@@ -0,0 +1,200 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+const (
+	secretTag = "secret"
+
+	secretMask = "******"
+
+	schemeSeparator = "://"
+)
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// Secret is a string config value which is never printed
+// use it for passwords and tokens: fmt, %+v and json print it masked,
+// real value is returned by Value
+//
+// value can be a reference resolved by SecretResolver at load time:
+// file:///run/secrets/db_pass or env://DB_PASS
+type Secret string
+
+// Value returns real secret value
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String implements fmt.Stringer and returns masked value
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return secretMask
+}
+
+// GoString implements fmt.GoStringer and returns masked value
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// MarshalJSON implements json.Marshaler and returns masked value
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// SecretResolver returns secret value by its reference
+type SecretResolver interface {
+	Resolve(reference string) (string, error)
+}
+
+// SecretResolverFunc is a function which implements SecretResolver
+type SecretResolverFunc func(reference string) (string, error)
+
+// Resolve calls f(reference)
+func (f SecretResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// SchemeResolver resolves references in scheme://path format with resolver registered for scheme
+// resolvers get reference without scheme, values without registered scheme are returned as is
+type SchemeResolver map[string]SecretResolver
+
+// Resolve implements SecretResolver
+func (r SchemeResolver) Resolve(reference string) (string, error) {
+	scheme, path, ok := strings.Cut(reference, schemeSeparator)
+	if !ok {
+		return reference, nil
+	}
+
+	resolver, ok := r[scheme]
+	if !ok {
+		return reference, nil
+	}
+
+	return resolver.Resolve(path)
+}
+
+// FileSecretResolver reads secret from file: file:///run/secrets/db_pass
+// trailing new line is trimmed
+var FileSecretResolver = SecretResolverFunc(func(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+})
+
+// EnvSecretResolver reads secret from environment variable: env://DB_PASS
+var EnvSecretResolver = SecretResolverFunc(func(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return value, nil
+})
+
+// DefaultSecretResolver resolves file:// and env:// references
+var DefaultSecretResolver = SchemeResolver{
+	"file": FileSecretResolver,
+	"env":  EnvSecretResolver,
+}
+
+// ResolveSecrets replaces references in fields of Secret type with values returned by resolver
+// string fields with `secret:"true"` tag are only masked by Describe and are not resolved,
+// because fmt would print resolved value of plain string
+// if resolver is nil, DefaultSecretResolver is used
+// config must be reference to your config structure
+func ResolveSecrets(config interface{}, resolver SecretResolver) error {
+	const fn = "config.ResolveSecrets"
+
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%s: config must be non-nil pointer to structure, got %T", fn, config)
+	}
+
+	if resolver == nil {
+		resolver = DefaultSecretResolver
+	}
+
+	if err := resolveStruct(value.Elem(), "", resolver); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	return nil
+}
+
+// IsSecretField reports whether field stores secret value: it has Secret type or `secret:"true"` tag
+func IsSecretField(field reflect.StructField) bool {
+	fieldType := field.Type
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	return fieldType == secretType || field.Tag.Get(secretTag) == "true"
+}
+
+// resolveStruct resolves secret fields of structure and its nested structures
+func resolveStruct(structValue reflect.Value, path string, resolver SecretResolver) error {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if _, ok := embeddedStructType(field); ok {
+			if err := resolveValue(structValue.Field(i), path, resolver); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		if err := resolveValue(structValue.Field(i), joinPath(path, name), resolver); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveValue resolves value if it is Secret or walks into it
+func resolveValue(value reflect.Value, path string, resolver SecretResolver) error {
+	switch {
+	case value.Kind() == reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+		return resolveValue(value.Elem(), path, resolver)
+	case value.Type() == secretType:
+		resolved, err := resolver.Resolve(value.String())
+		if err != nil {
+			return fmt.Errorf("unable to resolve secret %s: %v", path, err)
+		}
+		value.SetString(resolved)
+	case isNestedStruct(value.Type()):
+		return resolveStruct(value, path, resolver)
+	case value.Kind() == reflect.Slice || value.Kind() == reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if err := resolveValue(value.Index(i), elemPath, resolver); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
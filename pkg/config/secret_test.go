@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type secretTestConfig struct {
+	ListenPort int64  `json:"listen_port"`
+	DBPassword Secret `json:"db_password"`
+	APIToken   Secret `json:"api_token"`
+	Tagged     string `json:"tagged" secret:"true"`
+	Plain      string `json:"plain"`
+	Nested     struct {
+		Token *Secret `json:"token"`
+	} `json:"nested"`
+	Keys []Secret `json:"keys"`
+}
+
+func TestResolveSecrets(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "db_pass")
+	if err := os.WriteFile(secretFile, []byte("db-secret\n"), 0o600); err != nil {
+		t.Fatalf("unable to write secret file: %v", err)
+	}
+	t.Setenv("API_TOKEN", "api-secret")
+	t.Setenv("NESTED_TOKEN", "nested-secret")
+	t.Setenv("KEY", "key-secret")
+
+	nestedToken := Secret("env://NESTED_TOKEN")
+	config := secretTestConfig{
+		ListenPort: 10001,
+		DBPassword: Secret("file://" + secretFile),
+		APIToken:   "env://API_TOKEN",
+		Tagged:     "env://API_TOKEN",
+		Plain:      "env://API_TOKEN",
+		Keys:       []Secret{"env://KEY"},
+	}
+	config.Nested.Token = &nestedToken
+
+	if err := ResolveSecrets(&config, nil); err != nil {
+		t.Fatalf("ResolveSecrets() error = %v", err)
+	}
+
+	if config.DBPassword.Value() != "db-secret" {
+		t.Errorf("DBPassword = %q", config.DBPassword.Value())
+	}
+	if config.APIToken.Value() != "api-secret" {
+		t.Errorf("APIToken = %q", config.APIToken.Value())
+	}
+	// only Secret fields are resolved, fmt would print resolved value of tagged string
+	if config.Tagged != "env://API_TOKEN" {
+		t.Errorf("Tagged must not be resolved, got %q", config.Tagged)
+	}
+	if config.Plain != "env://API_TOKEN" {
+		t.Errorf("Plain must not be resolved, got %q", config.Plain)
+	}
+	if config.Nested.Token.Value() != "nested-secret" {
+		t.Errorf("Nested.Token = %q", config.Nested.Token.Value())
+	}
+	if len(config.Keys) != 1 || config.Keys[0].Value() != "key-secret" {
+		t.Errorf("Keys = %q", config.Keys)
+	}
+
+	// resolved values must never appear when config is printed
+	resolved := []string{"db-secret", "api-secret", "nested-secret", "key-secret"}
+	printed := []string{}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		printed = append(printed, fmt.Sprintf(format, config))
+	}
+	marshaled, _ := json.Marshal(config)
+	printed = append(printed, string(marshaled))
+	for _, output := range printed {
+		for _, value := range resolved {
+			if strings.Contains(output, value) {
+				t.Errorf("printed config leaks %s: %s", value, output)
+			}
+		}
+	}
+}
+
+func TestResolveSecretsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		password Secret
+		resolver SecretResolver
+		wantErr  bool
+	}{
+		{"literal", "password", nil, false},
+		{"unknown scheme", "vault://db/password", nil, false},
+		{"missing file", "file:///missing/secret", nil, true},
+		{"missing env", "env://SCRATCH_MISSING_SECRET", nil, true},
+		{"custom resolver", "vault://db/password", SchemeResolver{
+			"vault": SecretResolverFunc(func(reference string) (string, error) {
+				return "", fmt.Errorf("vault is unavailable")
+			}),
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := secretTestConfig{DBPassword: tt.password}
+			if err := ResolveSecrets(&config, tt.resolver); (err != nil) != tt.wantErr {
+				t.Errorf("ResolveSecrets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/levinishka/scratch/pkg/units"
+)
+
+var (
+	configDurationType = reflect.TypeOf(Duration(0))
+	byteSizeType       = reflect.TypeOf(ByteSize(0))
+)
+
+// Duration is a time.Duration config value which is read from strings like "250ms", "5s" or "1h30m"
+// in JSON, YAML, TOML, environment variables and flags, see units.Duration
+type Duration = units.Duration
+
+// ByteSize is a size in bytes config value which is read from strings like "512", "100KB" or "10MiB"
+// in JSON, YAML, TOML, environment variables and flags, see units.ByteSize
+type ByteSize = units.ByteSize
+
+// byte size units
+const (
+	Byte = units.Byte
+
+	KB = units.KB
+	MB = units.MB
+	GB = units.GB
+	TB = units.TB
+	PB = units.PB
+
+	KiB = units.KiB
+	MiB = units.MiB
+	GiB = units.GiB
+	TiB = units.TiB
+	PiB = units.PiB
+)
+
+// ParseByteSize parses size with optional unit: "512", "512B", "100KB", "1.5GiB"
+func ParseByteSize(s string) (ByteSize, error) {
+	return units.ParseByteSize(s)
+}
+
+// isDurationType reports whether t is time.Duration or Duration
+func isDurationType(t reflect.Type) bool {
+	return t == durationType || t == configDurationType
+}
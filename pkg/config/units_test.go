@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+type unitsTestConfig struct {
+	Timeout Duration `json:"timeout" validate:"min=100ms,max=1m"`
+	MaxBody ByteSize `json:"max_body" validate:"max=1GiB"`
+}
+
+func TestUnitsConfig(t *testing.T) {
+	want := unitsTestConfig{Timeout: Duration(250 * time.Millisecond), MaxBody: 10 * MiB}
+
+	config := unitsTestConfig{}
+	if err := NewConfig("test_files/units.yaml", &config); err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	if config != want {
+		t.Errorf("NewConfig() got = %+v, want %+v", config, want)
+	}
+
+	t.Setenv("TIMEOUT", "2s")
+	t.Setenv("MAX_BODY", "1KiB")
+	if err := ApplyEnv("", &config); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+	if config.Timeout.Duration() != 2*time.Second || config.MaxBody != KiB {
+		t.Errorf("ApplyEnv() got = %+v", config)
+	}
+
+	config.Timeout = Duration(time.Millisecond)
+	config.MaxBody = 2 * GiB
+	if err := Validate(config); err == nil || len(err.(ValidationErrors)) != 2 {
+		t.Errorf("Validate() error = %v, want 2 violations", err)
+	}
+}
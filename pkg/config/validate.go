@@ -0,0 +1,294 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const validateTag = "validate"
+
+// validation rules which can be used in validate tag, e.g. `validate:"required,min=1,max=65535"`
+const (
+	// RuleRequired fails on zero values, empty strings, slices and maps
+	RuleRequired = "required"
+	// RuleOmitEmpty skips all other rules for zero value
+	RuleOmitEmpty = "omitempty"
+	// RuleMin checks minimal number, duration, byte size or length of string, slice and map
+	RuleMin = "min"
+	// RuleMax checks maximal number, duration, byte size or length of string, slice and map
+	RuleMax = "max"
+	// RuleOneOf checks that value is one of space separated list: oneof=development production
+	RuleOneOf = "oneof"
+	// RuleHostPort checks that string is valid host:port pair
+	RuleHostPort = "hostport"
+	// RuleFileExists checks that string is path to existing file
+	RuleFileExists = "file-exists"
+)
+
+// FieldError describes one violated validation rule
+type FieldError struct {
+	// Path is a field path built from json names, e.g. db.hosts[0]
+	Path string
+	// Rule is a violated rule with its parameter, e.g. min=1
+	Rule string
+	// Message describes violation
+	Message string
+}
+
+// Error implements error interface
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// ValidationErrors stores all violations found in config
+type ValidationErrors []*FieldError
+
+// Error implements error interface
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldError := range e {
+		messages = append(messages, fieldError.Error())
+	}
+
+	return fmt.Sprintf("%d validation error(s): %s", len(e), strings.Join(messages, "; "))
+}
+
+// Validate checks config with rules from validate struct tags
+// and returns ValidationErrors with every violation or nil
+// config must be structure or reference to it
+func Validate(config interface{}) error {
+	const fn = "config.Validate"
+
+	value := reflect.ValueOf(config)
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("%s: config must be structure or reference to it, got %T", fn, config)
+	}
+
+	var errs ValidationErrors
+	validateStruct(value, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateStruct validates all fields of structure
+func validateStruct(structValue reflect.Value, path string, errs *ValidationErrors) {
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if _, ok := embeddedStructType(field); ok {
+			// fields of embedded structures are reported with paths of parent
+			validateValue(structValue.Field(i), path, field.Tag.Get(validateTag), errs)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		validateValue(structValue.Field(i), joinPath(path, name), field.Tag.Get(validateTag), errs)
+	}
+}
+
+// validateValue checks value with rules and walks into nested structures
+func validateValue(value reflect.Value, path string, rules string, errs *ValidationErrors) {
+	if rules != "" {
+		validateRules(value, path, rules, errs)
+	}
+
+	// walk into nested structures even if they have no rules
+	switch {
+	case value.Kind() == reflect.Pointer && !value.IsNil():
+		validateValue(value.Elem(), path, "", errs)
+	case isNestedStruct(value.Type()):
+		validateStruct(value, path, errs)
+	case value.Kind() == reflect.Slice || value.Kind() == reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elem := value.Index(i)
+			if isNestedStruct(elem.Type()) || elem.Kind() == reflect.Pointer {
+				validateValue(elem, fmt.Sprintf("%s[%d]", path, i), "", errs)
+			}
+		}
+	}
+}
+
+// validateRules checks value with comma separated rules
+func validateRules(value reflect.Value, path string, rules string, errs *ValidationErrors) {
+	ruleList := strings.Split(rules, ",")
+	for _, rule := range ruleList {
+		if rule == RuleOmitEmpty && value.IsZero() {
+			return
+		}
+	}
+
+	for _, rule := range ruleList {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == RuleOmitEmpty {
+			continue
+		}
+
+		if message := checkRule(value, name, param); message != "" {
+			*errs = append(*errs, &FieldError{Path: path, Rule: rule, Message: message})
+		}
+	}
+}
+
+// checkRule checks one rule and returns violation message or empty string
+func checkRule(value reflect.Value, name string, param string) string {
+	switch name {
+	case RuleRequired:
+		if isEmpty(value) {
+			return "is required"
+		}
+	case RuleMin, RuleMax:
+		return checkBound(value, name, param)
+	case RuleOneOf:
+		return forEachString(value, func(s string) string {
+			for _, allowed := range strings.Fields(param) {
+				if s == allowed {
+					return ""
+				}
+			}
+			return fmt.Sprintf("%q must be one of [%s]", s, param)
+		})
+	case RuleHostPort:
+		return forEachString(value, func(s string) string {
+			_, port, err := net.SplitHostPort(s)
+			if err != nil {
+				return fmt.Sprintf("%q is not valid host:port: %v", s, err)
+			}
+			if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+				return fmt.Sprintf("%q has invalid port", s)
+			}
+			return ""
+		})
+	case RuleFileExists:
+		return forEachString(value, func(s string) string {
+			info, err := os.Stat(s)
+			if err != nil {
+				return fmt.Sprintf("file %q does not exist", s)
+			}
+			if info.IsDir() {
+				return fmt.Sprintf("%q is a directory", s)
+			}
+			return ""
+		})
+	default:
+		return fmt.Sprintf("unknown validation rule %q", name)
+	}
+
+	return ""
+}
+
+// checkBound checks min or max rule
+func checkBound(value reflect.Value, name string, param string) string {
+	value = reflect.Indirect(value)
+	if !value.IsValid() {
+		return ""
+	}
+
+	var actual, bound float64
+	var err error
+	unit := ""
+
+	switch {
+	case isDurationType(value.Type()):
+		var duration time.Duration
+		duration, err = time.ParseDuration(param)
+		actual, bound = float64(value.Int()), float64(duration)
+	case value.Type() == byteSizeType:
+		var size ByteSize
+		size, err = ParseByteSize(param)
+		actual, bound = float64(value.Uint()), float64(size)
+	case value.CanInt():
+		actual = float64(value.Int())
+		bound, err = strconv.ParseFloat(param, 64)
+	case value.CanUint():
+		actual = float64(value.Uint())
+		bound, err = strconv.ParseFloat(param, 64)
+	case value.CanFloat():
+		actual = value.Float()
+		bound, err = strconv.ParseFloat(param, 64)
+	case value.Kind() == reflect.String || value.Kind() == reflect.Slice || value.Kind() == reflect.Map:
+		actual = float64(value.Len())
+		bound, err = strconv.ParseFloat(param, 64)
+		unit = " element(s)"
+		if value.Kind() == reflect.String {
+			unit = " character(s)"
+		}
+	default:
+		return fmt.Sprintf("rule %s is not supported for %s", name, value.Type())
+	}
+
+	if err != nil {
+		return fmt.Sprintf("invalid %s parameter %q: %v", name, param, err)
+	}
+	if name == RuleMin && actual < bound {
+		return fmt.Sprintf("must be at least %s%s", param, unit)
+	}
+	if name == RuleMax && actual > bound {
+		return fmt.Sprintf("must be at most %s%s", param, unit)
+	}
+
+	return ""
+}
+
+// forEachString applies check to string value or to every element of string slice
+// and returns first violation message
+func forEachString(value reflect.Value, check func(string) string) string {
+	value = reflect.Indirect(value)
+	if !value.IsValid() {
+		return ""
+	}
+
+	switch {
+	case value.Kind() == reflect.String:
+		return check(value.String())
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < value.Len(); i++ {
+			if message := check(value.Index(i).String()); message != "" {
+				return fmt.Sprintf("element %d: %s", i, message)
+			}
+		}
+		return ""
+	case value.CanInt():
+		return check(strconv.FormatInt(value.Int(), 10))
+	case value.CanUint():
+		return check(strconv.FormatUint(value.Uint(), 10))
+	default:
+		return fmt.Sprintf("rule is not supported for %s", value.Type())
+	}
+}
+
+// isEmpty reports whether value is zero or empty string, slice or map
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// joinPath appends field name to path
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type validateTestNested struct {
+	Address string `json:"address" validate:"hostport"`
+}
+
+type validateTestConfig struct {
+	ListenPort  int64                `json:"listen_port" validate:"min=1,max=65535"`
+	Timeout     time.Duration        `json:"timeout" validate:"min=0s,max=1m"`
+	LogEnv      string               `json:"log_env" validate:"omitempty,oneof=development production"`
+	Name        string               `json:"name" validate:"required"`
+	PathsToLogs []string             `json:"paths_to_logs" validate:"max=2"`
+	ConfigFile  string               `json:"config_file" validate:"omitempty,file-exists"`
+	Upstreams   []validateTestNested `json:"upstreams"`
+	Metrics     *validateTestNested  `json:"metrics"`
+}
+
+func TestValidate(t *testing.T) {
+	valid := validateTestConfig{
+		ListenPort:  10001,
+		Timeout:     time.Second,
+		LogEnv:      "production",
+		Name:        "service",
+		PathsToLogs: []string{"stderr"},
+		ConfigFile:  "test_files/config1.json",
+		Upstreams:   []validateTestNested{{"localhost:8080"}},
+		Metrics:     &validateTestNested{"localhost:8081"},
+	}
+
+	tests := []struct {
+		name      string
+		modify    func(c *validateTestConfig)
+		wantPaths []string
+	}{
+		{"valid", func(c *validateTestConfig) {}, nil},
+		{"omitempty", func(c *validateTestConfig) { c.LogEnv, c.ConfigFile = "", "" }, nil},
+		{"min", func(c *validateTestConfig) { c.ListenPort = 0 }, []string{"listen_port"}},
+		{"max", func(c *validateTestConfig) { c.ListenPort = 70000 }, []string{"listen_port"}},
+		{"duration", func(c *validateTestConfig) { c.Timeout = -time.Second }, []string{"timeout"}},
+		{"oneof", func(c *validateTestConfig) { c.LogEnv = "staging" }, []string{"log_env"}},
+		{"required", func(c *validateTestConfig) { c.Name = "" }, []string{"name"}},
+		{"length", func(c *validateTestConfig) { c.PathsToLogs = []string{"a", "b", "c"} }, []string{"paths_to_logs"}},
+		{"file-exists", func(c *validateTestConfig) { c.ConfigFile = "test_files/missing.json" }, []string{"config_file"}},
+		{"nested", func(c *validateTestConfig) {
+			c.Upstreams = []validateTestNested{{"localhost:8080"}, {"localhost"}}
+			c.Metrics = &validateTestNested{"localhost:0"}
+		}, []string{"upstreams[1].address", "metrics.address"}},
+		{"aggregated", func(c *validateTestConfig) {
+			c.ListenPort, c.LogEnv, c.Name = -1, "test", ""
+		}, []string{"listen_port", "log_env", "name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+
+			err := Validate(&config)
+			if tt.wantPaths == nil {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error = %v", err)
+				}
+				return
+			}
+
+			var validationErrors ValidationErrors
+			if !errors.As(err, &validationErrors) {
+				t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+			}
+
+			var paths []string
+			for _, fieldError := range validationErrors {
+				paths = append(paths, fieldError.Path)
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("Validate() paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestNewStrictConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		configFile string
+		wantErr    bool
+	}{
+		{"known keys", "test_files/config1.json", false},
+		{"unknown keys", "test_files/config2.json", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig{}
+			if err := NewStrictConfig(tt.configFile, &config); (err != nil) != tt.wantErr {
+				t.Errorf("NewStrictConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := NewConfig(tt.configFile, &testConfig{}); err != nil {
+				t.Errorf("NewConfig() unexpected error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// reloadDelay is used to collapse bursts of change events from editors and config map updates into one reload
+	reloadDelay = 100 * time.Millisecond
+	// loadTimeout limits reading of config, so stalled source doesn't block reloads forever
+	loadTimeout = time.Minute
+)
+
+// LoadFunc reads configFile to config
+// config.NewConfig and config.NewStrictConfig can be used as LoadFunc
+type LoadFunc func(configFile string, config interface{}) error
+
+// EnvLoader returns LoadFunc which reads config with NewConfigWithEnv
+func EnvLoader(envPrefix string) LoadFunc {
+	return func(configFile string, config interface{}) error {
+		return NewConfigWithEnv(configFile, envPrefix, config)
+	}
+}
+
+// Watcher reloads config of type T when its source changes or process receives SIGHUP
+// every reload reads config into fresh structure and validates it:
+// invalid configs are logged and never replace current value
+type Watcher[T any] struct {
+	// load reads config into fresh structure, it must stop when ctx is done
+	load func(ctx context.Context, config interface{}) error
+	// sources notify about config changes
+	sources []WatchingProvider
+
+	current atomic.Pointer[T]
+
+	// reloadMu serializes reloads, so older config never replaces newer one
+	reloadMu sync.Mutex
+	// mu guards subscribers and config swap
+	mu          sync.Mutex
+	subscribers []func(oldConfig, newConfig *T)
+
+	logger *zap.SugaredLogger
+
+	// ctx is cancelled by Close to stop watching and running reloads
+	ctx     context.Context
+	cancel  context.CancelFunc
+	changes chan struct{}
+	signals chan os.Signal
+	wg      sync.WaitGroup
+}
+
+// NewWatcher creates Watcher of configFile and reads initial config with load
+// if load is nil, NewConfig is used
+func NewWatcher[T any](configFile string, load LoadFunc) (*Watcher[T], error) {
+	const fn = "config.NewWatcher"
+
+	if load == nil {
+		load = NewConfig
+	}
+
+	w, err := newWatcher[T](func(_ context.Context, config interface{}) error {
+		return load(configFile, config)
+	}, NewFileProvider(configFile))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+
+	return w, nil
+}
+
+// NewLoaderWatcher creates Watcher which reads config with loader
+// and reloads it on changes of every file returned by loader.Files
+func NewLoaderWatcher[T any](loader *Loader) (*Watcher[T], error) {
+	const fn = "config.NewLoaderWatcher"
+
+	files := loader.Files()
+	sources := make([]WatchingProvider, 0, len(files))
+	for _, file := range files {
+		sources = append(sources, NewFileProvider(file))
+	}
+
+	w, err := newWatcher[T](func(_ context.Context, config interface{}) error {
+		_, err := loader.Load(config)
+		return err
+	}, sources...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+
+	return w, nil
+}
+
+// NewProviderWatcher creates Watcher of config from provider and reads initial config with NewConfigFromProvider
+// if provider implements WatchingProvider, config is reloaded on its changes, otherwise only on SIGHUP
+// reads are cancelled by Close and limited with timeout
+func NewProviderWatcher[T any](provider Provider) (*Watcher[T], error) {
+	const fn = "config.NewProviderWatcher"
+
+	var sources []WatchingProvider
+	if source, ok := provider.(WatchingProvider); ok {
+		sources = append(sources, source)
+	}
+	w, err := newWatcher[T](func(ctx context.Context, config interface{}) error {
+		return NewConfigFromProvider(ctx, provider, config)
+	}, sources...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn, err)
+	}
+
+	return w, nil
+}
+
+// newWatcher creates Watcher of sources and reads initial config with load
+func newWatcher[T any](load func(ctx context.Context, config interface{}) error, sources ...WatchingProvider) (*Watcher[T], error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Watcher[T]{
+		load:    load,
+		sources: sources,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	config, err := w.read()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("unable to read initial config: %v", err)
+	}
+	w.current.Store(config)
+
+	return w, nil
+}
+
+// read reads config into fresh structure with load timeout
+func (w *Watcher[T]) read() (*T, error) {
+	ctx, cancel := context.WithTimeout(w.ctx, loadTimeout)
+	defer cancel()
+
+	config := new(T)
+	if err := w.load(ctx, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Config returns current config
+// returned structure is shared between all callers and must not be modified
+func (w *Watcher[T]) Config() *T {
+	return w.current.Load()
+}
+
+// Subscribe registers callback which is called after every successful reload
+// with previous and new configs
+func (w *Watcher[T]) Subscribe(callback func(oldConfig, newConfig *T)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, callback)
+}
+
+// Start starts watching config source and SIGHUP signal
+// reload and watching errors are written to sugarLogger
+func (w *Watcher[T]) Start(sugarLogger *zap.SugaredLogger) error {
+	const fn = "config.Watcher.Start"
+
+	if w.ctx.Err() != nil {
+		return fmt.Errorf("%s: watcher is closed", fn)
+	}
+	if w.changes != nil {
+		return fmt.Errorf("%s: watcher is already started", fn)
+	}
+
+	if sugarLogger == nil {
+		sugarLogger = zap.NewNop().Sugar()
+	}
+	w.logger = sugarLogger
+
+	w.changes = make(chan struct{}, 1)
+	w.signals = make(chan os.Signal, 1)
+
+	for _, source := range w.sources {
+		err := source.Watch(w.ctx, func() {
+			// reload is already pending if channel is full
+			select {
+			case w.changes <- struct{}{}:
+			default:
+			}
+		})
+		if err != nil {
+			w.cancel()
+			return fmt.Errorf("%s: %v", fn, err)
+		}
+	}
+
+	signal.Notify(w.signals, syscall.SIGHUP)
+
+	w.wg.Add(1)
+	go w.watch(w.ctx)
+
+	return nil
+}
+
+// Close stops watching, cancels running reload and waits for it to finish
+// Watcher can't be started again after Close
+func (w *Watcher[T]) Close() {
+	if w.signals != nil {
+		signal.Stop(w.signals)
+	}
+	w.cancel()
+	w.wg.Wait()
+}
+
+// Reload reads config and swaps it with current one if it is valid
+// subscribers are notified only if config has been changed
+func (w *Watcher[T]) Reload() error {
+	const fn = "config.Watcher.Reload"
+
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	// config is read without mu, so stalled source doesn't block Subscribe
+	newConfig, err := w.read()
+	if err != nil {
+		return fmt.Errorf("%s: config is rejected: %v", fn, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	oldConfig := w.current.Load()
+	if reflect.DeepEqual(oldConfig, newConfig) {
+		return nil
+	}
+
+	w.current.Store(newConfig)
+	for _, subscriber := range w.subscribers {
+		subscriber(oldConfig, newConfig)
+	}
+
+	return nil
+}
+
+// watch handles changes and signals until ctx is done
+func (w *Watcher[T]) watch(ctx context.Context) {
+	const fn = "watch"
+
+	defer w.wg.Done()
+
+	// timer collapses several changes into one reload
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.signals:
+			w.logger.Infof("%s: SIGHUP received, reloading config", fn)
+			w.reload()
+		case <-w.changes:
+			timer.Reset(reloadDelay)
+		case <-timer.C:
+			w.logger.Infof("%s: config changed, reloading", fn)
+			w.reload()
+		}
+	}
+}
+
+// reload reloads config and logs result
+func (w *Watcher[T]) reload() {
+	const fn = "reload"
+
+	if err := w.Reload(); err != nil {
+		w.logger.Errorf("%s: %v", fn, err)
+		return
+	}
+
+	w.logger.Infof("%s: config reloaded", fn)
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type watcherTestConfig struct {
+	ListenPort int64  `json:"listen_port" validate:"min=1"`
+	LogLevel   string `json:"log_level"`
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("unable to write %s: %v", path, err)
+	}
+}
+
+func TestWatcherReload(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeTestFile(t, configFile, `{"listen_port": 10001, "log_level": "info"}`)
+
+	watcher, err := NewWatcher[watcherTestConfig](configFile, nil)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	calls := 0
+	watcher.Subscribe(func(oldConfig, newConfig *watcherTestConfig) {
+		calls++
+		if oldConfig.LogLevel != "info" || newConfig.LogLevel != "debug" {
+			t.Errorf("Subscribe() got old = %+v, new = %+v", oldConfig, newConfig)
+		}
+	})
+
+	// invalid config must be rejected
+	writeTestFile(t, configFile, `{"listen_port": 0, "log_level": "debug"}`)
+	if err := watcher.Reload(); err == nil {
+		t.Errorf("Reload() expected error for invalid config")
+	}
+	if got := watcher.Config(); got.ListenPort != 10001 || got.LogLevel != "info" {
+		t.Errorf("Config() after rejected reload = %+v", got)
+	}
+
+	// unchanged config must not notify subscribers
+	writeTestFile(t, configFile, `{"listen_port": 10001, "log_level": "info"}`)
+	if err := watcher.Reload(); err != nil {
+		t.Errorf("Reload() error = %v", err)
+	}
+
+	writeTestFile(t, configFile, `{"listen_port": 10001, "log_level": "debug"}`)
+	if err := watcher.Reload(); err != nil {
+		t.Errorf("Reload() error = %v", err)
+	}
+	if got := watcher.Config(); got.LogLevel != "debug" {
+		t.Errorf("Config() after reload = %+v", got)
+	}
+	if calls != 1 {
+		t.Errorf("Subscribe() callback called %d times, want 1", calls)
+	}
+}
+
+func TestWatcherWatchFile(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeTestFile(t, configFile, `{"listen_port": 10001, "log_level": "info"}`)
+
+	watcher, err := NewWatcher[watcherTestConfig](configFile, nil)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	reloaded := make(chan *watcherTestConfig, 1)
+	watcher.Subscribe(func(_, newConfig *watcherTestConfig) {
+		reloaded <- newConfig
+	})
+
+	if err := watcher.Start(nil); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer watcher.Close()
+
+	writeTestFile(t, configFile, `{"listen_port": 10002, "log_level": "info"}`)
+
+	select {
+	case newConfig := <-reloaded:
+		if newConfig.ListenPort != 10002 {
+			t.Errorf("reloaded config = %+v", newConfig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("config has not been reloaded")
+	}
+}
+
+func TestLoaderWatcherWatchEnvironmentFile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	writeTestFile(t, configFile, `{"listen_port": 10001, "log_level": "info"}`)
+	writeTestFile(t, EnvironmentFile(configFile, "production"), `{"log_level": "warn"}`)
+
+	loader := &Loader{ConfigFile: configFile, Environment: "production"}
+	if got, want := loader.Files(), []string{configFile, filepath.Join(dir, "config.production.json")}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Files() = %v, want %v", got, want)
+	}
+
+	watcher, err := NewLoaderWatcher[watcherTestConfig](loader)
+	if err != nil {
+		t.Fatalf("NewLoaderWatcher() error = %v", err)
+	}
+	if got := watcher.Config(); got.LogLevel != "warn" {
+		t.Fatalf("Config() = %+v", got)
+	}
+
+	reloaded := make(chan *watcherTestConfig, 1)
+	watcher.Subscribe(func(_, newConfig *watcherTestConfig) {
+		reloaded <- newConfig
+	})
+
+	if err := watcher.Start(nil); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer watcher.Close()
+
+	writeTestFile(t, EnvironmentFile(configFile, "production"), `{"log_level": "debug"}`)
+
+	select {
+	case newConfig := <-reloaded:
+		if newConfig.ListenPort != 10001 || newConfig.LogLevel != "debug" {
+			t.Errorf("reloaded config = %+v", newConfig)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("config has not been reloaded after change of environment file")
+	}
+}
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"bufio"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/levinishka/scratch/pkg/metrics/logmetrics"
+	"github.com/levinishka/scratch/pkg/units"
+)
+
+const (
+	defaultAsyncBufferSize    = 1024
+	defaultAsyncFlushInterval = time.Second
+
+	// asyncWriterSize is a size of buffer in which entries are collected before writing
+	asyncWriterSize = 256 * 1024
+)
+
+// AsyncOptions configures asynchronous writing of log entries
+type AsyncOptions struct {
+	// BufferSize is a number of entries which wait for writing, 1024 by default
+	BufferSize int `json:"buffer_size" description:"number of entries which wait for writing, 1024 by default"`
+	// FlushInterval is an interval in which collected entries are written, 1s by default
+	FlushInterval units.Duration `json:"flush_interval" description:"interval in which collected entries are written, 1s by default"`
+	// DropWhenFull drops entries when buffer is full instead of blocking logger calls
+	DropWhenFull bool `json:"drop_when_full" description:"drop entries when buffer is full instead of blocking"`
+}
+
+// AsyncWriteSyncer is a zapcore.WriteSyncer which writes entries in its own goroutine
+// entries are written every flush interval and on Sync, e.g. zap syncs outputs after error entries
+// dropped entries are counted in logmetrics.DroppedEntriesTotal
+type AsyncWriteSyncer struct {
+	writer       zapcore.WriteSyncer
+	dropWhenFull bool
+
+	entries chan []byte
+	syncs   chan chan error
+	done    chan struct{}
+
+	// mu guards stopped, entries channel is closed under write lock
+	mu      sync.RWMutex
+	stopped bool
+}
+
+// NewAsyncWriteSyncer creates AsyncWriteSyncer which writes to writer and starts its goroutine
+func NewAsyncWriteSyncer(writer zapcore.WriteSyncer, options AsyncOptions) *AsyncWriteSyncer {
+	bufferSize := options.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	flushInterval := options.FlushInterval.Duration()
+	if flushInterval <= 0 {
+		flushInterval = defaultAsyncFlushInterval
+	}
+
+	w := &AsyncWriteSyncer{
+		writer:       writer,
+		dropWhenFull: options.DropWhenFull,
+		entries:      make(chan []byte, bufferSize),
+		syncs:        make(chan chan error),
+		done:         make(chan struct{}),
+	}
+	go w.run(flushInterval)
+
+	return w
+}
+
+// Write implements zapcore.WriteSyncer
+// it blocks when buffer is full or drops entry if DropWhenFull is set
+// after Stop entries are written synchronously
+func (w *AsyncWriteSyncer) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.stopped {
+		return w.writer.Write(p)
+	}
+
+	// zap reuses buffers of entries
+	entry := make([]byte, len(p))
+	copy(entry, p)
+
+	if !w.dropWhenFull {
+		w.entries <- entry
+		return len(p), nil
+	}
+
+	select {
+	case w.entries <- entry:
+	default:
+		logmetrics.DroppedEntriesTotal.Inc()
+	}
+
+	return len(p), nil
+}
+
+// Sync implements zapcore.WriteSyncer
+// it writes all entries which have been written before and syncs writer
+// it returns error of writing if entries have not been written since previous Sync
+func (w *AsyncWriteSyncer) Sync() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.stopped {
+		return w.writer.Sync()
+	}
+
+	result := make(chan error, 1)
+	w.syncs <- result
+
+	return <-result
+}
+
+// Stop writes all entries, syncs writer and stops goroutine
+// it is safe to use AsyncWriteSyncer after Stop, entries are written synchronously then
+func (w *AsyncWriteSyncer) Stop() error {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		return nil
+	}
+	w.stopped = true
+	close(w.entries)
+	w.mu.Unlock()
+
+	<-w.done
+
+	return w.writer.Sync()
+}
+
+// run writes entries until entries channel is closed
+func (w *AsyncWriteSyncer) run(flushInterval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	buffer := bufio.NewWriterSize(w.writer, asyncWriterSize)
+	// err is the first error of writing since previous Sync
+	var err error
+	write := func(entry []byte) {
+		if _, writeErr := buffer.Write(entry); writeErr != nil && err == nil {
+			err = writeErr
+		}
+	}
+	flush := func() {
+		if flushErr := buffer.Flush(); flushErr != nil {
+			if err == nil {
+				err = flushErr
+			}
+			// bufio.Writer keeps error forever, so buffer is replaced to write next entries
+			buffer = bufio.NewWriterSize(w.writer, asyncWriterSize)
+		}
+	}
+
+	for {
+		select {
+		case entry, ok := <-w.entries:
+			if !ok {
+				flush()
+				return
+			}
+			write(entry)
+		case result := <-w.syncs:
+			// entries written before Sync are already in channel
+			for pending := len(w.entries); pending > 0; pending-- {
+				write(<-w.entries)
+			}
+			flush()
+			if syncErr := w.writer.Sync(); syncErr != nil && err == nil {
+				err = syncErr
+			}
+			result <- err
+			err = nil
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
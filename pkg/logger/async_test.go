@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/levinishka/scratch/pkg/metrics/logmetrics"
+	"github.com/levinishka/scratch/pkg/units"
+)
+
+// testWriteSyncer is a zapcore.WriteSyncer which blocks writes until it is released
+type testWriteSyncer struct {
+	mu      sync.Mutex
+	buffer  bytes.Buffer
+	release chan struct{}
+}
+
+func (w *testWriteSyncer) Write(p []byte) (int, error) {
+	if w.release != nil {
+		<-w.release
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buffer.Write(p)
+}
+
+func (w *testWriteSyncer) Sync() error {
+	return nil
+}
+
+func (w *testWriteSyncer) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buffer.String()
+}
+
+func TestAsyncWriteSyncer(t *testing.T) {
+	writer := &testWriteSyncer{}
+	asyncWriter := NewAsyncWriteSyncer(writer, AsyncOptions{FlushInterval: units.Duration(time.Hour)})
+
+	_, _ = asyncWriter.Write([]byte("first\n"))
+	_, _ = asyncWriter.Write([]byte("second\n"))
+	if got := writer.String(); got != "" {
+		t.Errorf("entries are written before Sync: %q", got)
+	}
+
+	if err := asyncWriter.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := writer.String(); got != "first\nsecond\n" {
+		t.Errorf("written after Sync = %q", got)
+	}
+
+	_, _ = asyncWriter.Write([]byte("third\n"))
+	if err := asyncWriter.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	// entries are written synchronously after Stop
+	_, _ = asyncWriter.Write([]byte("fourth\n"))
+	if got := writer.String(); got != "first\nsecond\nthird\nfourth\n" {
+		t.Errorf("written after Stop = %q", got)
+	}
+}
+
+func TestAsyncWriteSyncerFlushInterval(t *testing.T) {
+	writer := &testWriteSyncer{}
+	asyncWriter := NewAsyncWriteSyncer(writer, AsyncOptions{FlushInterval: units.Duration(10 * time.Millisecond)})
+	defer func() {
+		_ = asyncWriter.Stop()
+	}()
+
+	_, _ = asyncWriter.Write([]byte("flushed\n"))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for writer.String() == "" {
+		if time.Now().After(deadline) {
+			t.Fatalf("entry has not been flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAsyncWriteSyncerDropWhenFull(t *testing.T) {
+	writer := &testWriteSyncer{release: make(chan struct{})}
+	asyncWriter := NewAsyncWriteSyncer(writer, AsyncOptions{
+		BufferSize:    2,
+		FlushInterval: units.Duration(time.Hour),
+		DropWhenFull:  true,
+	})
+	before := testutil.ToFloat64(logmetrics.DroppedEntriesTotal)
+
+	// entries fill bufio.Writer and then it blocks on write to writer
+	entry := []byte(strings.Repeat("x", asyncWriterSize/2) + "\n")
+	for i := 0; i < 10; i++ {
+		_, _ = asyncWriter.Write(entry)
+	}
+
+	if got := testutil.ToFloat64(logmetrics.DroppedEntriesTotal) - before; got == 0 {
+		t.Errorf("dropped entries = 0, want > 0")
+	}
+
+	close(writer.release)
+	if err := asyncWriter.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
+
+func TestNewAsync(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+
+	handle, err := New(
+		WithEnvironment(Production),
+		WithOutputs(logFile),
+		WithAsync(&AsyncOptions{FlushInterval: units.Duration(time.Hour)}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	handle.Sugar().Info("buffered")
+	if data, _ := os.ReadFile(logFile); len(data) != 0 {
+		t.Errorf("log is written before Sync: %s", data)
+	}
+
+	// entries are written on Sync of logger
+	_ = handle.Logger().Sync()
+	if data, _ := os.ReadFile(logFile); !strings.Contains(string(data), "buffered") {
+		t.Errorf("log after Sync = %s", data)
+	}
+}
+
+func TestHandleClose(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+
+	handle, err := New(
+		WithOutputs(logFile),
+		WithAsync(&AsyncOptions{FlushInterval: units.Duration(time.Hour)}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	handle.Sugar().Info("buffered")
+	if err := handle.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if data, _ := os.ReadFile(logFile); !strings.Contains(string(data), "buffered") {
+		t.Errorf("log after Close = %s", data)
+	}
+
+	// repeated Close and writing after it don't panic
+	if err := handle.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	handle.Sugar().Info("after close")
+}
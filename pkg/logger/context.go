@@ -0,0 +1,42 @@
+package logger
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is a key of logger in context
+type contextKey struct{}
+
+// WithContext returns copy of ctx which stores sugarLogger
+func WithContext(ctx context.Context, sugarLogger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, contextKey{}, sugarLogger)
+}
+
+// fallback is created on first use, logger without options never fails
+var fallback = sync.OnceValue(func() *zap.SugaredLogger {
+	handle, err := New()
+	if err != nil {
+		return zap.NewNop().Sugar()
+	}
+
+	return handle.Sugar()
+})
+
+// Fallback returns logger which is used when no logger is given:
+// it writes entries of info level and above to stderr in json
+func Fallback() *zap.SugaredLogger {
+	return fallback()
+}
+
+// FromContext returns logger stored in ctx by WithContext
+// if there is no logger in ctx, Fallback logger is returned
+func FromContext(ctx context.Context) *zap.SugaredLogger {
+	if sugarLogger, ok := ctx.Value(contextKey{}).(*zap.SugaredLogger); ok && sugarLogger != nil {
+		return sugarLogger
+	}
+
+	return Fallback()
+}
@@ -0,0 +1,234 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	zaplogfmt "github.com/jsternberg/zap-logfmt"
+	"go.uber.org/zap/zapcore"
+)
+
+// encodings
+const (
+	EncodingJSON    = "json"
+	EncodingConsole = "console"
+	EncodingLogfmt  = "logfmt"
+)
+
+// encoder presets
+const (
+	// FormatDefault writes message, level, time and caller keys with ISO8601 time and capital levels
+	FormatDefault = "default"
+	// FormatECS matches Elastic Common Schema layout
+	FormatECS = "ecs"
+	// FormatGCP matches GCP Cloud Logging structured logs layout
+	FormatGCP = "gcp"
+	// FormatLogfmt writes key=value pairs
+	FormatLogfmt = "logfmt"
+)
+
+// time formats
+const (
+	TimeFormatISO8601     = "iso8601"
+	TimeFormatRFC3339     = "rfc3339"
+	TimeFormatRFC3339Nano = "rfc3339nano"
+	TimeFormatEpoch       = "epoch"
+	TimeFormatEpochMillis = "epoch_millis"
+	TimeFormatEpochNanos  = "epoch_nanos"
+)
+
+// level formats
+const (
+	LevelFormatCapital        = "capital"
+	LevelFormatCapitalColor   = "capital_color"
+	LevelFormatLowercase      = "lowercase"
+	LevelFormatLowercaseColor = "lowercase_color"
+	// LevelFormatGCP writes GCP Cloud Logging severities: DEBUG, INFO, WARNING, ERROR, CRITICAL, ALERT, EMERGENCY
+	LevelFormatGCP = "gcp"
+)
+
+// defaultStacktraceLevel is high enough to keep stacktraces out of logs of usual errors
+const defaultStacktraceLevel = "dpanic"
+
+// Encoder configures encoding of log entries
+// empty keys are not written
+type Encoder struct {
+	// Encoding is json, console or logfmt
+	Encoding      string `json:"encoding"`
+	MessageKey    string `json:"message_key"`
+	LevelKey      string `json:"level_key"`
+	TimeKey       string `json:"time_key"`
+	NameKey       string `json:"name_key"`
+	CallerKey     string `json:"caller_key"`
+	StacktraceKey string `json:"stacktrace_key"`
+	// TimeFormat is iso8601, rfc3339, rfc3339nano, epoch, epoch_millis or epoch_nanos
+	TimeFormat string `json:"time_format"`
+	// LevelFormat is capital, capital_color, lowercase, lowercase_color or gcp
+	LevelFormat string `json:"level_format"`
+	// StacktraceLevel is a level from which stacktraces are written
+	StacktraceLevel string `json:"stacktrace_level"`
+}
+
+// DefaultEncoder returns encoder of FormatDefault preset with encoding
+func DefaultEncoder(encoding string) Encoder {
+	return Encoder{
+		Encoding:        encoding,
+		MessageKey:      "message",
+		LevelKey:        "level",
+		TimeKey:         "time",
+		CallerKey:       "caller",
+		StacktraceKey:   "stacktrace",
+		TimeFormat:      TimeFormatISO8601,
+		LevelFormat:     LevelFormatCapital,
+		StacktraceLevel: defaultStacktraceLevel,
+	}
+}
+
+// EncoderPreset returns encoder of preset with name: default, ecs, gcp or logfmt
+// empty name means default preset
+func EncoderPreset(name string) (Encoder, error) {
+	switch strings.ToLower(name) {
+	case "", FormatDefault:
+		return DefaultEncoder(EncodingJSON), nil
+	case FormatECS:
+		return Encoder{
+			Encoding:        EncodingJSON,
+			MessageKey:      "message",
+			LevelKey:        "log.level",
+			TimeKey:         "@timestamp",
+			NameKey:         "log.logger",
+			CallerKey:       "log.origin.file.name",
+			StacktraceKey:   "error.stack_trace",
+			TimeFormat:      TimeFormatISO8601,
+			LevelFormat:     LevelFormatLowercase,
+			StacktraceLevel: defaultStacktraceLevel,
+		}, nil
+	case FormatGCP:
+		return Encoder{
+			Encoding:        EncodingJSON,
+			MessageKey:      "message",
+			LevelKey:        "severity",
+			TimeKey:         "timestamp",
+			NameKey:         "logger",
+			CallerKey:       "caller",
+			StacktraceKey:   "stack_trace",
+			TimeFormat:      TimeFormatRFC3339Nano,
+			LevelFormat:     LevelFormatGCP,
+			StacktraceLevel: defaultStacktraceLevel,
+		}, nil
+	case FormatLogfmt:
+		return Encoder{
+			Encoding:        EncodingLogfmt,
+			MessageKey:      "msg",
+			LevelKey:        "level",
+			TimeKey:         "ts",
+			NameKey:         "logger",
+			CallerKey:       "caller",
+			StacktraceKey:   "stacktrace",
+			TimeFormat:      TimeFormatRFC3339Nano,
+			LevelFormat:     LevelFormatLowercase,
+			StacktraceLevel: defaultStacktraceLevel,
+		}, nil
+	default:
+		return Encoder{}, fmt.Errorf("unknown log format %q, use %s, %s, %s or %s",
+			name, FormatDefault, FormatECS, FormatGCP, FormatLogfmt)
+	}
+}
+
+// zapConfig returns zap encoder config and level from which stacktraces are written
+func (e Encoder) zapConfig() (zapcore.EncoderConfig, zapcore.Level, error) {
+	encoderConfig := zapcore.EncoderConfig{
+		MessageKey:     e.MessageKey,
+		LevelKey:       e.LevelKey,
+		TimeKey:        e.TimeKey,
+		NameKey:        e.NameKey,
+		CallerKey:      e.CallerKey,
+		StacktraceKey:  e.StacktraceKey,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	}
+
+	switch strings.ToLower(e.TimeFormat) {
+	case "", TimeFormatISO8601:
+		encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	case TimeFormatRFC3339:
+		encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
+	case TimeFormatRFC3339Nano:
+		encoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	case TimeFormatEpoch:
+		encoderConfig.EncodeTime = zapcore.EpochTimeEncoder
+	case TimeFormatEpochMillis:
+		encoderConfig.EncodeTime = epochMillisTimeEncoder
+	case TimeFormatEpochNanos:
+		encoderConfig.EncodeTime = zapcore.EpochNanosTimeEncoder
+	default:
+		return encoderConfig, 0, fmt.Errorf("unknown time format %q", e.TimeFormat)
+	}
+
+	switch strings.ToLower(e.LevelFormat) {
+	case "", LevelFormatCapital:
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	case LevelFormatCapitalColor:
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	case LevelFormatLowercase:
+		encoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+	case LevelFormatLowercaseColor:
+		encoderConfig.EncodeLevel = zapcore.LowercaseColorLevelEncoder
+	case LevelFormatGCP:
+		encoderConfig.EncodeLevel = gcpLevelEncoder
+	default:
+		return encoderConfig, 0, fmt.Errorf("unknown level format %q", e.LevelFormat)
+	}
+
+	stacktraceLevel := e.StacktraceLevel
+	if stacktraceLevel == "" {
+		stacktraceLevel = defaultStacktraceLevel
+	}
+	level, ok := LevelNamesMap[strings.ToLower(stacktraceLevel)]
+	if !ok {
+		return encoderConfig, 0, fmt.Errorf("unknown stacktrace level %q", e.StacktraceLevel)
+	}
+
+	return encoderConfig, level, nil
+}
+
+// newZapEncoder creates zapcore.Encoder of encoding with encoderConfig
+func newZapEncoder(encoding string, encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch strings.ToLower(encoding) {
+	case "", EncodingJSON:
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	case EncodingConsole:
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	case EncodingLogfmt:
+		return zaplogfmt.NewEncoder(encoderConfig), nil
+	default:
+		return nil, fmt.Errorf("unknown log encoding %q, use %s, %s or %s",
+			encoding, EncodingJSON, EncodingConsole, EncodingLogfmt)
+	}
+}
+
+// epochMillisTimeEncoder writes time as integer number of milliseconds since epoch
+func epochMillisTimeEncoder(t time.Time, encoder zapcore.PrimitiveArrayEncoder) {
+	encoder.AppendInt64(t.UnixMilli())
+}
+
+// gcpSeverities stores mapping from zapcore.Level to GCP Cloud Logging severity
+var gcpSeverities = map[zapcore.Level]string{
+	zapcore.DebugLevel:  "DEBUG",
+	zapcore.InfoLevel:   "INFO",
+	zapcore.WarnLevel:   "WARNING",
+	zapcore.ErrorLevel:  "ERROR",
+	zapcore.DPanicLevel: "CRITICAL",
+	zapcore.PanicLevel:  "ALERT",
+	zapcore.FatalLevel:  "EMERGENCY",
+}
+
+// gcpLevelEncoder writes level as GCP Cloud Logging severity
+func gcpLevelEncoder(level zapcore.Level, encoder zapcore.PrimitiveArrayEncoder) {
+	severity, ok := gcpSeverities[level]
+	if !ok {
+		severity = "DEFAULT"
+	}
+	encoder.AppendString(severity)
+}
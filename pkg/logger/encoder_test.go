@@ -0,0 +1,120 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncoderPresets(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		encoder  *Encoder
+		wantKeys map[string]string
+		wantLine string
+		wantErr  bool
+	}{
+		{
+			name:   "default",
+			format: FormatDefault,
+			wantKeys: map[string]string{
+				"message": "test message", "level": "WARN", "time": "", "caller": "", "service": "test",
+			},
+		},
+		{
+			name:   "ecs",
+			format: FormatECS,
+			wantKeys: map[string]string{
+				"message": "test message", "log.level": "warn", "@timestamp": "", "log.origin.file.name": "", "service": "test",
+			},
+		},
+		{
+			name:   "gcp",
+			format: FormatGCP,
+			wantKeys: map[string]string{
+				"message": "test message", "severity": "WARNING", "timestamp": "", "caller": "", "service": "test",
+			},
+		},
+		{
+			name:     "logfmt",
+			format:   FormatLogfmt,
+			wantLine: `msg="test message" service=test`,
+		},
+		{
+			name: "custom encoder",
+			encoder: &Encoder{
+				Encoding:   EncodingJSON,
+				MessageKey: "msg",
+				TimeKey:    "ts",
+				TimeFormat: TimeFormatEpochMillis,
+			},
+			wantKeys: map[string]string{"msg": "test message", "ts": "", "service": "test"},
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: true,
+		},
+		{
+			name:    "unknown time format",
+			encoder: &Encoder{Encoding: EncodingJSON, TimeFormat: "unix"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logFile := filepath.Join(t.TempDir(), "log")
+			var err error
+			encoder := tt.encoder
+			if encoder == nil {
+				var preset Encoder
+				preset, err = EncoderPreset(tt.format)
+				encoder = &preset
+			}
+			var handle *Handle
+			if err == nil {
+				handle, err = New(WithEnvironment(Production), WithOutputs(logFile), WithEncoder(*encoder))
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			handle.Sugar().Warnw("test message", "service", "test")
+			_ = handle.Logger().Sync()
+
+			data, err := os.ReadFile(logFile)
+			if err != nil {
+				t.Fatalf("unable to read log: %v", err)
+			}
+			line := strings.TrimSpace(string(data))
+
+			if tt.wantLine != "" && !strings.Contains(line, tt.wantLine) {
+				t.Errorf("log line = %s, want to contain %s", line, tt.wantLine)
+			}
+			if tt.wantKeys == nil {
+				return
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("unable to decode log line %s: %v", line, err)
+			}
+			if len(entry) != len(tt.wantKeys) {
+				t.Errorf("log entry = %v, want keys %v", entry, tt.wantKeys)
+			}
+			for key, want := range tt.wantKeys {
+				got, ok := entry[key]
+				if !ok || (want != "" && got != want) {
+					t.Errorf("log entry key %s = %v, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
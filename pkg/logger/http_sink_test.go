@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// testLogServer stores requests to it and responds with statuses one by one, then with 200
+type testLogServer struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (s *testLogServer) ServeHTTP(respWriter http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	s.bodies = append(s.bodies, string(body))
+
+	if len(s.statuses) > 0 {
+		respWriter.WriteHeader(s.statuses[0])
+		s.statuses = s.statuses[1:]
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		statuses   []int
+		wantPath   string
+		wantType   string
+		wantBodies []string
+		wantErr    bool
+	}{
+		{
+			name:       "json",
+			query:      "?batch_size=2&flush_interval=1h&source=app",
+			wantPath:   "/logs?source=app",
+			wantType:   "application/json",
+			wantBodies: []string{`[{"msg":"first"},{"msg":"second"}]`, `[{"msg":"third"}]`},
+		},
+		{
+			name:       "ndjson",
+			query:      "?format=ndjson&flush_interval=1h",
+			wantPath:   "/logs",
+			wantType:   "application/x-ndjson",
+			wantBodies: []string{"{\"msg\":\"first\"}\n{\"msg\":\"second\"}\n{\"msg\":\"third\"}\n"},
+		},
+		{
+			name:     "elasticsearch",
+			query:    "?format=elasticsearch&index=logs&flush_interval=1h",
+			wantPath: "/logs",
+			wantType: "application/x-ndjson",
+			wantBodies: []string{
+				"{\"index\":{\"_index\":\"logs\"}}\n{\"msg\":\"first\"}\n{\"index\":{\"_index\":\"logs\"}}\n{\"msg\":\"second\"}\n" +
+					"{\"index\":{\"_index\":\"logs\"}}\n{\"msg\":\"third\"}\n",
+			},
+		},
+		{
+			name:     "retry",
+			query:    "?format=ndjson&retry_wait=1ms&flush_interval=1h",
+			statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+			wantPath: "/logs",
+			wantType: "application/x-ndjson",
+			wantBodies: []string{
+				"{\"msg\":\"first\"}\n{\"msg\":\"second\"}\n{\"msg\":\"third\"}\n",
+				"{\"msg\":\"first\"}\n{\"msg\":\"second\"}\n{\"msg\":\"third\"}\n",
+				"{\"msg\":\"first\"}\n{\"msg\":\"second\"}\n{\"msg\":\"third\"}\n",
+			},
+		},
+		{
+			name:       "not retried",
+			query:      "?format=ndjson&retry_wait=1ms&flush_interval=1h",
+			statuses:   []int{http.StatusBadRequest},
+			wantPath:   "/logs",
+			wantType:   "application/x-ndjson",
+			wantBodies: []string{"{\"msg\":\"first\"}\n{\"msg\":\"second\"}\n{\"msg\":\"third\"}\n"},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logServer := &testLogServer{statuses: tt.statuses}
+			httpServer := httptest.NewServer(logServer)
+			defer httpServer.Close()
+
+			u, err := url.Parse(strings.Replace(httpServer.URL, "http://", "http+json://", 1) + "/logs" + tt.query)
+			if err != nil {
+				t.Fatalf("unable to parse url: %v", err)
+			}
+			sink, err := newHTTPSink(u)
+			if err != nil {
+				t.Fatalf("newHTTPSink() error = %v", err)
+			}
+			defer sink.Close()
+
+			_, _ = sink.Write([]byte("{\"msg\":\"first\"}\n"))
+			// several entries can be written at once by asynchronous writer
+			_, _ = sink.Write([]byte("{\"msg\":\"second\"}\n{\"msg\":\"third\"}\n"))
+			if err := sink.Sync(); (err != nil) != tt.wantErr {
+				t.Errorf("Sync() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			logServer.mu.Lock()
+			defer logServer.mu.Unlock()
+
+			if len(logServer.bodies) != len(tt.wantBodies) {
+				t.Fatalf("requests = %q, want %q", logServer.bodies, tt.wantBodies)
+			}
+			for i, body := range logServer.bodies {
+				if body != tt.wantBodies[i] {
+					t.Errorf("request %d body = %q, want %q", i, body, tt.wantBodies[i])
+				}
+				if got := logServer.requests[i].URL.RequestURI(); got != tt.wantPath {
+					t.Errorf("request %d path = %s, want %s", i, got, tt.wantPath)
+				}
+				if got := logServer.requests[i].Header.Get("Content-Type"); got != tt.wantType {
+					t.Errorf("request %d content type = %s, want %s", i, got, tt.wantType)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPSinkLoki(t *testing.T) {
+	logServer := &testLogServer{}
+	httpServer := httptest.NewServer(logServer)
+	defer httpServer.Close()
+
+	path := strings.Replace(httpServer.URL, "http://", "http+json://user:password@", 1) +
+		"/loki/api/v1/push?format=loki&labels=job:app,env:test&flush_interval=1h"
+	handle, err := New(WithOutputs(path))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	handle.Sugar().Info("shipped")
+	_ = handle.Logger().Sync()
+
+	logServer.mu.Lock()
+	defer logServer.mu.Unlock()
+
+	if len(logServer.requests) != 1 {
+		t.Fatalf("requests = %q, want 1 request", logServer.bodies)
+	}
+	if user, password, ok := logServer.requests[0].BasicAuth(); !ok || user != "user" || password != "password" {
+		t.Errorf("basic auth = %s:%s", user, password)
+	}
+
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal([]byte(logServer.bodies[0]), &push); err != nil {
+		t.Fatalf("unable to decode request %s: %v", logServer.bodies[0], err)
+	}
+	if len(push.Streams) != 1 || push.Streams[0].Stream["job"] != "app" || push.Streams[0].Stream["env"] != "test" {
+		t.Fatalf("streams = %+v", push.Streams)
+	}
+	if values := push.Streams[0].Values; len(values) != 1 || !strings.Contains(values[0][1], `"message":"shipped"`) {
+		t.Errorf("values = %q", values)
+	}
+}
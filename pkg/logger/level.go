@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Level is a logger level which can be changed at runtime permanently or for some time
+type Level struct {
+	atomicLevel zap.AtomicLevel
+
+	// mu guards pending revert of temporary level
+	mu          sync.Mutex
+	revertLevel zapcore.Level
+	revertAt    time.Time
+	revertTimer *time.Timer
+}
+
+// NewLevel creates new Level with level
+func NewLevel(level zapcore.Level) *Level {
+	return &Level{atomicLevel: zap.NewAtomicLevelAt(level)}
+}
+
+// AtomicLevel returns zap.AtomicLevel which is used by logger
+func (l *Level) AtomicLevel() zap.AtomicLevel {
+	return l.atomicLevel
+}
+
+// Level returns current level
+func (l *Level) Level() zapcore.Level {
+	return l.atomicLevel.Level()
+}
+
+// SetLevel changes level permanently and cancels pending revert of temporary level
+func (l *Level) SetLevel(level zapcore.Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stopRevert()
+	l.atomicLevel.SetLevel(level)
+}
+
+// SetTemporaryLevel changes level for ttl, after that level is reverted to the last permanent one
+func (l *Level) SetTemporaryLevel(level zapcore.Level, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// keep permanent level if temporary level is changed again
+	revertLevel := l.atomicLevel.Level()
+	if l.revertTimer != nil {
+		revertLevel = l.revertLevel
+		l.stopRevert()
+	}
+
+	l.revertLevel = revertLevel
+	l.revertAt = time.Now().Add(ttl)
+	l.atomicLevel.SetLevel(level)
+
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		// revert has been canceled or replaced
+		if l.revertTimer != timer {
+			return
+		}
+		l.atomicLevel.SetLevel(l.revertLevel)
+		l.revertTimer = nil
+	})
+	l.revertTimer = timer
+}
+
+// stopRevert cancels pending revert, mu must be held
+func (l *Level) stopRevert() {
+	if l.revertTimer != nil {
+		l.revertTimer.Stop()
+		l.revertTimer = nil
+	}
+}
+
+// levelState is a Level representation in HTTP API
+type levelState struct {
+	Level       string     `json:"level"`
+	RevertLevel string     `json:"revert_level,omitempty"`
+	RevertAt    *time.Time `json:"revert_at,omitempty"`
+}
+
+// levelRequest is a body of level change request
+// TTL is a duration like "10m", level is changed permanently if it is empty
+type levelRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl"`
+}
+
+// levelError is an error response of HTTP API
+type levelError struct {
+	Error string `json:"error"`
+}
+
+// ServeHTTP implements http.Handler
+// GET returns current level and pending revert, PUT changes level with body like
+// {"level": "debug", "ttl": "10m"}, ttl is optional
+func (l *Level) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		if err := l.change(r); err != nil {
+			writeLevelJSON(w, http.StatusBadRequest, levelError{Error: err.Error()})
+			return
+		}
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodPut)
+		writeLevelJSON(w, http.StatusMethodNotAllowed, levelError{Error: "only GET and PUT are supported"})
+		return
+	}
+
+	writeLevelJSON(w, http.StatusOK, l.state())
+}
+
+// change changes level with request r
+func (l *Level) change(r *http.Request) error {
+	var request levelRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return fmt.Errorf("unable to decode request: %v", err)
+	}
+
+	level, ok := LevelNamesMap[strings.ToLower(request.Level)]
+	if !ok {
+		return fmt.Errorf("unknown level %q", request.Level)
+	}
+
+	if request.TTL == "" {
+		l.SetLevel(level)
+		return nil
+	}
+
+	ttl, err := time.ParseDuration(request.TTL)
+	if err != nil || ttl <= 0 {
+		return fmt.Errorf("ttl must be a positive duration like \"10m\", got %q", request.TTL)
+	}
+	l.SetTemporaryLevel(level, ttl)
+
+	return nil
+}
+
+// state returns current level and pending revert
+func (l *Level) state() levelState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := levelState{Level: l.atomicLevel.Level().String()}
+	if l.revertTimer != nil {
+		revertAt := l.revertAt
+		state.RevertLevel = l.revertLevel.String()
+		state.RevertAt = &revertAt
+	}
+
+	return state
+}
+
+// writeLevelJSON writes value as JSON response with status
+func writeLevelJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelServeHTTP(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantLevel  string
+		wantRevert bool
+	}{
+		{
+			name:       "get",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantLevel:  "info",
+		},
+		{
+			name:       "set permanent level",
+			method:     http.MethodPut,
+			body:       `{"level": "warn"}`,
+			wantStatus: http.StatusOK,
+			wantLevel:  "warn",
+		},
+		{
+			name:       "set temporary level",
+			method:     http.MethodPut,
+			body:       `{"level": "DEBUG", "ttl": "1h"}`,
+			wantStatus: http.StatusOK,
+			wantLevel:  "debug",
+			wantRevert: true,
+		},
+		{
+			name:       "unknown level",
+			method:     http.MethodPut,
+			body:       `{"level": "verbose"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid ttl",
+			method:     http.MethodPut,
+			body:       `{"level": "debug", "ttl": "-1m"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported method",
+			method:     http.MethodPost,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level := NewLevel(zapcore.InfoLevel)
+
+			recorder := httptest.NewRecorder()
+			level.ServeHTTP(recorder, httptest.NewRequest(tt.method, "/debug/loglevel", strings.NewReader(tt.body)))
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("ServeHTTP() status = %d, want %d, body %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var state levelState
+			if err := json.Unmarshal(recorder.Body.Bytes(), &state); err != nil {
+				t.Fatalf("unable to decode response: %v", err)
+			}
+			if state.Level != tt.wantLevel || level.Level().String() != tt.wantLevel {
+				t.Errorf("ServeHTTP() level = %s, current level = %s, want %s", state.Level, level.Level(), tt.wantLevel)
+			}
+			if (state.RevertAt != nil) != tt.wantRevert {
+				t.Errorf("ServeHTTP() revert_at = %v, wantRevert %v", state.RevertAt, tt.wantRevert)
+			}
+		})
+	}
+}
+
+func TestLevelSetTemporaryLevel(t *testing.T) {
+	level := NewLevel(zapcore.InfoLevel)
+
+	level.SetTemporaryLevel(zapcore.WarnLevel, time.Hour)
+	// the second temporary level must be reverted to permanent level, not to the first temporary one
+	level.SetTemporaryLevel(zapcore.DebugLevel, 10*time.Millisecond)
+	if got := level.Level(); got != zapcore.DebugLevel {
+		t.Fatalf("Level() = %s, want debug", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for level.Level() != zapcore.InfoLevel {
+		if time.Now().After(deadline) {
+			t.Fatalf("level has not been reverted, Level() = %s", level.Level())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// permanent level cancels pending revert
+	level.SetTemporaryLevel(zapcore.DebugLevel, 10*time.Millisecond)
+	level.SetLevel(zapcore.ErrorLevel)
+	time.Sleep(50 * time.Millisecond)
+	if got := level.Level(); got != zapcore.ErrorLevel {
+		t.Errorf("Level() after SetLevel = %s, want error", got)
+	}
+}
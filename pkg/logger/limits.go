@@ -0,0 +1,252 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/levinishka/scratch/pkg/units"
+)
+
+const (
+	defaultSamplingTick = time.Second
+
+	// maxRateLimitMessages limits number of messages which are tracked by rate limiter,
+	// messages above limit are not rate limited
+	maxRateLimitMessages = 10000
+)
+
+// Sampling configures sampling of entries with one level:
+// in every Tick first Initial entries with the same message are written and then every Thereafter-th of them
+type Sampling struct {
+	Initial    int            `json:"initial" description:"number of entries with the same message written in every tick"`
+	Thereafter int            `json:"thereafter" description:"every thereafter-th entry is written after initial ones, 0 drops all of them"`
+	Tick       units.Duration `json:"tick" description:"sampling interval, 1s by default"`
+}
+
+// RateLimit configures rate limiting of entries with the same level and message:
+// entries above Burst in every Interval are dropped, and after Interval
+// summary "suppressed N similar messages" is written
+type RateLimit struct {
+	Burst    int            `json:"burst" description:"number of entries with the same message written in every interval"`
+	Interval units.Duration `json:"interval" description:"rate limiting interval"`
+}
+
+// Limits configures sampling and rate limiting of logger
+// dpanic, panic and fatal entries are never rate limited
+type Limits struct {
+	// Sampling stores sampling by level names, entries of other levels are not sampled
+	Sampling map[string]Sampling `json:"sampling,omitempty" description:"sampling by level names"`
+	// RateLimit is applied to entries of all levels, nil disables it
+	RateLimit *RateLimit `json:"rate_limit,omitempty" description:"rate limiting of entries with the same message"`
+}
+
+// ProductionLimits returns limits of production preset: debug and info entries are sampled and
+// entries with the same message are written at most 100 times per second
+func ProductionLimits() *Limits {
+	sampling := Sampling{Initial: 100, Thereafter: 100, Tick: units.Duration(time.Second)}
+
+	return &Limits{
+		Sampling: map[string]Sampling{
+			"debug": sampling,
+			"info":  sampling,
+		},
+		RateLimit: &RateLimit{Burst: 100, Interval: units.Duration(time.Second)},
+	}
+}
+
+// EnvironmentLimits returns limits of environment preset
+// development logger is not limited, so all entries are visible during debugging
+func EnvironmentLimits(environment string) *Limits {
+	if strings.ToLower(environment) == Development {
+		return nil
+	}
+
+	return ProductionLimits()
+}
+
+// wrapCore returns core with sampling and rate limiting of limits
+func (l *Limits) wrapCore(core zapcore.Core) (zapcore.Core, error) {
+	if l == nil {
+		return core, nil
+	}
+
+	limitedCore := core
+	if len(l.Sampling) > 0 {
+		samplers := make(map[zapcore.Level]zapcore.Core, len(l.Sampling))
+		for levelName, sampling := range l.Sampling {
+			level, ok := LevelNamesMap[strings.ToLower(levelName)]
+			if !ok {
+				return nil, fmt.Errorf("unknown sampling level %q", levelName)
+			}
+			tick := sampling.Tick.Duration()
+			if tick <= 0 {
+				tick = defaultSamplingTick
+			}
+			samplers[level] = zapcore.NewSamplerWithOptions(core, tick, sampling.Initial, sampling.Thereafter)
+		}
+		limitedCore = &levelSamplerCore{Core: core, samplers: samplers}
+	}
+
+	if l.RateLimit != nil && l.RateLimit.Burst > 0 && l.RateLimit.Interval > 0 {
+		limitedCore = &rateLimitCore{
+			Core: limitedCore,
+			limiter: &rateLimiter{
+				burst:    l.RateLimit.Burst,
+				interval: l.RateLimit.Interval.Duration(),
+				core:     core,
+				messages: make(map[rateLimitKey]*rateLimitWindow),
+			},
+		}
+	}
+
+	return limitedCore, nil
+}
+
+// levelSamplerCore samples entries with sampler of their level
+type levelSamplerCore struct {
+	zapcore.Core
+	samplers map[zapcore.Level]zapcore.Core
+}
+
+// With implements zapcore.Core
+func (c *levelSamplerCore) With(fields []zapcore.Field) zapcore.Core {
+	samplers := make(map[zapcore.Level]zapcore.Core, len(c.samplers))
+	for level, sampler := range c.samplers {
+		samplers[level] = sampler.With(fields)
+	}
+
+	return &levelSamplerCore{Core: c.Core.With(fields), samplers: samplers}
+}
+
+// Check implements zapcore.Core
+func (c *levelSamplerCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if sampler, ok := c.samplers[entry.Level]; ok {
+		return sampler.Check(entry, checked)
+	}
+
+	return c.Core.Check(entry, checked)
+}
+
+// rateLimitCore drops entries which are not allowed by limiter
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+}
+
+// With implements zapcore.Core
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+// Check implements zapcore.Core
+func (c *rateLimitCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	if entry.Level < zapcore.DPanicLevel && !c.limiter.allow(entry) {
+		return checked
+	}
+
+	return c.Core.Check(entry, checked)
+}
+
+// rateLimitKey identifies similar entries
+type rateLimitKey struct {
+	level   zapcore.Level
+	message string
+}
+
+// rateLimitWindow counts similar entries in current interval
+type rateLimitWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// rateLimiter counts entries by their level and message and writes summaries of suppressed ones
+// it is shared by all children of rateLimitCore
+type rateLimiter struct {
+	burst    int
+	interval time.Duration
+	// core writes summaries
+	core zapcore.Core
+
+	mu       sync.Mutex
+	messages map[rateLimitKey]*rateLimitWindow
+}
+
+// allow reports whether entry can be written
+func (l *rateLimiter) allow(entry zapcore.Entry) bool {
+	key := rateLimitKey{level: entry.Level, message: entry.Message}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	window, ok := l.messages[key]
+	if !ok {
+		if len(l.messages) >= maxRateLimitMessages && !l.removeExpired(entry.Time) {
+			return true
+		}
+		window = &rateLimitWindow{start: entry.Time}
+		l.messages[key] = window
+	}
+	if entry.Time.Sub(window.start) >= l.interval {
+		window.start = entry.Time
+		window.count = 0
+	}
+
+	window.count++
+	if window.count <= l.burst {
+		return true
+	}
+
+	// summary is written once after window ends
+	if window.suppressed == 0 {
+		time.AfterFunc(window.start.Add(l.interval).Sub(entry.Time), func() {
+			l.report(key, window)
+		})
+	}
+	window.suppressed++
+
+	return false
+}
+
+// removeExpired removes windows which ended before now without suppressed entries
+// and reports whether any window has been removed, mu must be held
+func (l *rateLimiter) removeExpired(now time.Time) bool {
+	removed := false
+	for key, window := range l.messages {
+		if window.suppressed == 0 && now.Sub(window.start) >= l.interval {
+			delete(l.messages, key)
+			removed = true
+		}
+	}
+
+	return removed
+}
+
+// report writes summary of entries suppressed in window
+func (l *rateLimiter) report(key rateLimitKey, window *rateLimitWindow) {
+	l.mu.Lock()
+	suppressed := window.suppressed
+	window.suppressed = 0
+	l.mu.Unlock()
+
+	if suppressed == 0 {
+		return
+	}
+
+	entry := zapcore.Entry{
+		Level:   key.level,
+		Time:    time.Now(),
+		Message: fmt.Sprintf("suppressed %d similar messages", suppressed),
+	}
+	if checked := l.core.Check(entry, nil); checked != nil {
+		checked.Write(zap.String("suppressed_message", key.message), zap.Int("suppressed", suppressed))
+	}
+}
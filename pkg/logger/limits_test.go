@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/levinishka/scratch/pkg/units"
+)
+
+func TestLimitsSampling(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	limits := &Limits{
+		Sampling: map[string]Sampling{
+			"info": {Initial: 2, Thereafter: 3, Tick: units.Duration(time.Hour)},
+		},
+	}
+	limitedCore, err := limits.wrapCore(core)
+	if err != nil {
+		t.Fatalf("wrapCore() error = %v", err)
+	}
+	loggerInstance := zap.New(limitedCore).With(zap.String("service", "test"))
+
+	for i := 0; i < 10; i++ {
+		loggerInstance.Info("sampled")
+		loggerInstance.Warn("not sampled")
+	}
+
+	// first 2 entries and then every 3rd: 1, 2, 5, 8
+	if got := logs.FilterMessage("sampled").Len(); got != 4 {
+		t.Errorf("got %d info entries, want 4", got)
+	}
+	if got := logs.FilterMessage("not sampled").Len(); got != 10 {
+		t.Errorf("got %d warn entries, want 10", got)
+	}
+	if got := logs.FilterField(zap.String("service", "test")).Len(); got != 14 {
+		t.Errorf("got %d entries with fields, want 14", got)
+	}
+
+	if _, err := (&Limits{Sampling: map[string]Sampling{"verbose": {}}}).wrapCore(core); err == nil {
+		t.Errorf("wrapCore() with unknown level error = nil")
+	}
+}
+
+func TestLimitsRateLimit(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	limits := &Limits{
+		RateLimit: &RateLimit{Burst: 3, Interval: units.Duration(50 * time.Millisecond)},
+	}
+	limitedCore, err := limits.wrapCore(core)
+	if err != nil {
+		t.Fatalf("wrapCore() error = %v", err)
+	}
+	loggerInstance := zap.New(limitedCore)
+
+	for i := 0; i < 10; i++ {
+		loggerInstance.Error("flood")
+		loggerInstance.Error("other")
+		loggerInstance.With(zap.Int("child", i)).Error("flood")
+	}
+
+	if got := logs.FilterMessage("flood").Len(); got != 3 {
+		t.Errorf("got %d flood entries, want 3", got)
+	}
+	if got := logs.FilterMessage("other").Len(); got != 3 {
+		t.Errorf("got %d other entries, want 3", got)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.FilterMessageSnippet("suppressed").Len() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("summaries have not been written, entries: %v", logs.All())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	summaries := logs.FilterMessage("suppressed 17 similar messages").FilterField(zap.String("suppressed_message", "flood"))
+	if summaries.Len() != 1 || summaries.All()[0].Level != zapcore.ErrorLevel {
+		t.Errorf("flood summary = %v", logs.FilterMessageSnippet("suppressed").All())
+	}
+	if logs.FilterMessage("suppressed 7 similar messages").FilterField(zap.String("suppressed_message", "other")).Len() != 1 {
+		t.Errorf("other summary = %v", logs.FilterMessageSnippet("suppressed").All())
+	}
+
+	// limits are reset in the next interval
+	loggerInstance.Error("flood")
+	if got := logs.FilterMessage("flood").Len(); got != 4 {
+		t.Errorf("got %d flood entries after interval, want 4", got)
+	}
+}
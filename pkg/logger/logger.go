@@ -1,8 +1,13 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"os"
 	"strings"
+	"sync"
 
+	"github.com/levinishka/scratch/pkg/metrics/logmetrics"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -34,44 +39,248 @@ var LevelNamesMap = map[string]zapcore.Level{
 	"fatal":  zapcore.FatalLevel,
 }
 
+// Handle stores logger with its Level which can be changed at runtime
+type Handle struct {
+	logger *zap.Logger
+	level  *Level
+
+	// close stops asynchronous writers and closes outputs
+	close     func() error
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Logger returns zap.Logger
+func (h *Handle) Logger() *zap.Logger {
+	return h.logger
+}
+
+// Sugar returns zap.SugaredLogger
+func (h *Handle) Sugar() *zap.SugaredLogger {
+	return h.logger.Sugar()
+}
+
+// Level returns logger level
+func (h *Handle) Level() *Level {
+	return h.level
+}
+
+// Close syncs logger, stops asynchronous writers and closes outputs opened by logger
+// logger must not be used after Close, repeated calls return result of the first one
+func (h *Handle) Close() error {
+	const fn = "logger.Handle.Close"
+
+	h.closeOnce.Do(func() {
+		// sync errors of stdout and stderr are common, so they are not returned
+		_ = h.logger.Sync()
+		if err := h.close(); err != nil {
+			h.closeErr = fmt.Errorf("%s: %v", fn, err)
+		}
+	})
+
+	return h.closeErr
+}
+
+// New creates new zap.Logger with opts and returns it with its Level
+// without options logger writes entries of info level and above to stderr in json
+func New(opts ...Option) (*Handle, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	isDevelopment := o.development || strings.ToLower(o.environment) == Development
+
+	logLevel := o.level
+	if logLevel == "" {
+		logLevel = defaultProductionLevel
+		if isDevelopment {
+			logLevel = defaultDevelopmentLevel
+		}
+	}
+	level := NewLevel(parseLevel(logLevel))
+
+	encoder := DefaultEncoder(defaultProductionEncoding)
+	switch {
+	case o.encoder != nil:
+		encoder = *o.encoder
+	case isDevelopment:
+		encoder = DefaultEncoder(defaultDevelopmentEncoding)
+	}
+
+	limits := o.limits
+	if limits == nil && o.environment != "" {
+		limits = EnvironmentLimits(o.environment)
+	}
+
+	pathsToLogs := o.pathsToLogs
+	if isDevelopment {
+		pathsToLogs = withStdOutOrErr(pathsToLogs)
+	}
+
+	loggerInstance, closeOutputs, err := newLogger(level.AtomicLevel(), pathsToLogs, encoder, limits, o.async, o.cores)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.countMessages {
+		counter := o.counter
+		if counter == nil {
+			counter = logmetrics.MessagesTotal
+		}
+		loggerInstance = loggerInstance.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return &metricsCore{Core: core, counter: counter}
+		}))
+	}
+
+	zapOptions := []zap.Option{zap.AddCallerSkip(o.callerSkip)}
+	if isDevelopment {
+		zapOptions = append(zapOptions, zap.Development())
+	}
+	// fields and hooks are added after cores, so they are applied to all of them
+	if len(o.fields) > 0 {
+		zapOptions = append(zapOptions, zap.Fields(o.fields...))
+	}
+	if len(o.hooks) > 0 {
+		zapOptions = append(zapOptions, zap.Hooks(o.hooks...))
+	}
+	zapOptions = append(zapOptions, o.zapOptions...)
+
+	return &Handle{logger: loggerInstance.WithOptions(zapOptions...), level: level, close: closeOutputs}, nil
+}
+
 // NewLogger creates new zap.Logger
-// if pathToLogs is empty, stdout will be used
-func NewLogger(logLevel string, pathsToLogs []string, encoding string) (*zap.Logger, error) {
+// if pathToLogs is empty, stderr will be used, opts are applied after arguments, e.g. WithMetrics
+func NewLogger(logLevel string, pathsToLogs []string, encoding string, opts ...Option) (*zap.Logger, error) {
+	handle, err := New(append([]Option{
+		WithLevel(logLevel), WithOutputs(pathsToLogs...), WithEncoder(DefaultEncoder(encoding)),
+	}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return handle.Logger(), nil
+}
+
+// parseLevel returns zapcore.Level by its name or default level if name is unknown
+func parseLevel(logLevel string) zapcore.Level {
 	zapLogLevel, ok := LevelNamesMap[strings.ToLower(logLevel)]
 	if !ok {
 		// set default log level
 		zapLogLevel = defaultLogLevel
 	}
 
+	return zapLogLevel
+}
+
+// withStdOutOrErr adds stderr to pathsToLogs if they have neither stdout nor stderr
+func withStdOutOrErr(pathsToLogs []string) []string {
+	for _, path := range pathsToLogs {
+		if path == stdout || path == stderr {
+			return pathsToLogs
+		}
+	}
+
+	return append(pathsToLogs, stderr)
+}
+
+// newLogger creates new zap.Logger which writes entries enabled by level with encoder to pathsToLogs and cores
+// entries written to pathsToLogs are sampled and rate limited with limits if they are not nil
+// and written asynchronously with async options if they are not nil
+// returned function stops asynchronous writer and closes pathsToLogs
+func newLogger(level zap.AtomicLevel, pathsToLogs []string, encoder Encoder, limits *Limits, async *AsyncOptions, cores []zapcore.Core) (*zap.Logger, func() error, error) {
+	const fn = "logger.newLogger"
+
 	if len(pathsToLogs) == 0 {
 		pathsToLogs = []string{stderr}
 	}
 
-	loggerConfig := zap.Config{
-		Level:    zap.NewAtomicLevelAt(zapLogLevel),
-		Encoding: encoding,
-		EncoderConfig: zapcore.EncoderConfig{
-			MessageKey: "message",
+	encoderConfig, stacktraceLevel, err := encoder.zapConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	zapEncoder, err := newZapEncoder(encoder.Encoding, encoderConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// syslog paths are written with their own cores, so severity of messages is chosen by entry level
+	var outputPaths, syslogPaths []string
+	for _, path := range pathsToLogs {
+		if isSyslogPath(path) {
+			syslogPaths = append(syslogPaths, path)
+		} else {
+			outputPaths = append(outputPaths, path)
+		}
+	}
+
+	var (
+		outputCores []zapcore.Core
+		closers     []func() error
+		// internal errors of zap are written synchronously
+		errorOutput zapcore.WriteSyncer = zapcore.Lock(os.Stderr)
+	)
+	closeOutputs := func() error {
+		var errs []error
+		for _, closeOutput := range closers {
+			errs = append(errs, closeOutput())
+		}
+		return errors.Join(errs...)
+	}
+
+	if len(outputPaths) > 0 {
+		sink, closeSink, err := zap.Open(outputPaths...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: unable to open outputs: %v", fn, err)
+		}
+		output, closeOutput := withAsync(sink, closeSink, async)
+		outputCores = append(outputCores, zapcore.NewCore(zapEncoder, output, level))
+		closers = append(closers, closeOutput)
+		errorOutput = sink
+	}
+	for _, path := range syslogPaths {
+		core, closeOutput, err := newSyslogCore(path, zapEncoder.Clone(), level, async)
+		if err != nil {
+			_ = closeOutputs()
+			return nil, nil, fmt.Errorf("%s: unable to open outputs: %v", fn, err)
+		}
+		outputCores = append(outputCores, core)
+		closers = append(closers, closeOutput)
+	}
 
-			LevelKey:    "level",
-			EncodeLevel: zapcore.CapitalLevelEncoder,
+	limitedCore, err := limits.wrapCore(zapcore.NewTee(outputCores...))
+	if err != nil {
+		_ = closeOutputs()
+		return nil, nil, err
+	}
+	if len(cores) > 0 {
+		limitedCore = zapcore.NewTee(append([]zapcore.Core{limitedCore}, cores...)...)
+	}
 
-			TimeKey:    "time",
-			EncodeTime: zapcore.ISO8601TimeEncoder,
+	return zap.New(limitedCore, zap.ErrorOutput(errorOutput), zap.AddCaller(), zap.AddStacktrace(stacktraceLevel)), closeOutputs, nil
+}
 
-			CallerKey:    "caller",
-			EncodeCaller: zapcore.ShortCallerEncoder,
-		},
-		OutputPaths:      pathsToLogs,
-		ErrorOutputPaths: pathsToLogs,
+// withAsync wraps output with AsyncWriteSyncer if async options are not nil
+// returned function stops asynchronous writer and then closes output with closeOutput
+func withAsync(output zapcore.WriteSyncer, closeOutput func(), async *AsyncOptions) (zapcore.WriteSyncer, func() error) {
+	if async == nil {
+		return output, func() error {
+			closeOutput()
+			return nil
+		}
 	}
 
-	return loggerConfig.Build()
+	asyncOutput := NewAsyncWriteSyncer(output, *async)
+	return asyncOutput, func() error {
+		err := asyncOutput.Stop()
+		closeOutput()
+		return err
+	}
 }
 
 // NewSugarLogger creates new zap.SugaredLogger
-func NewSugarLogger(logLevel string, pathsToLogs []string, encoding string) (*zap.SugaredLogger, error) {
-	loggerInstance, err := NewLogger(logLevel, pathsToLogs, encoding)
+func NewSugarLogger(logLevel string, pathsToLogs []string, encoding string, opts ...Option) (*zap.SugaredLogger, error) {
+	loggerInstance, err := NewLogger(logLevel, pathsToLogs, encoding, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -80,41 +289,34 @@ func NewSugarLogger(logLevel string, pathsToLogs []string, encoding string) (*za
 }
 
 // NewDevelopmentSugarLogger creates new zap.SugaredLogger to use it during development
-func NewDevelopmentSugarLogger(pathsToLogs []string) (*zap.SugaredLogger, error) {
-	// add stderr to logger paths in development logger
-	isStdOutOrErr := false
-	for _, path := range pathsToLogs {
-		if isStdOutOrErr = path == stdout || path == stderr; isStdOutOrErr {
-			break
-		}
-	}
-	if !isStdOutOrErr {
-		pathsToLogs = append(pathsToLogs, stderr)
-	}
-
-	// create base zap logger
-	loggerInstance, err := NewLogger(defaultDevelopmentLevel, pathsToLogs, defaultDevelopmentEncoding)
+func NewDevelopmentSugarLogger(pathsToLogs []string, opts ...Option) (*zap.SugaredLogger, error) {
+	handle, err := New(append([]Option{WithDevelopment(), WithOutputs(pathsToLogs...)}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
 
-	// add development option
-	return loggerInstance.WithOptions(zap.Development()).Sugar(), nil
+	return handle.Sugar(), nil
 }
 
 // NewProductionSugarLogger creates new zap.SugaredLogger to use it in production
-func NewProductionSugarLogger(pathsToLogs []string) (*zap.SugaredLogger, error) {
-	return NewSugarLogger(defaultProductionLevel, pathsToLogs, defaultProductionEncoding)
+// entries are sampled and rate limited with ProductionLimits
+func NewProductionSugarLogger(pathsToLogs []string, opts ...Option) (*zap.SugaredLogger, error) {
+	handle, err := New(append([]Option{WithEnvironment(Production), WithOutputs(pathsToLogs...)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return handle.Sugar(), nil
 }
 
 // NewEnvironmentSugarLogger creates new zap.SugaredLogger for environment
-func NewEnvironmentSugarLogger(environment string, pathsToLogs []string) (*zap.SugaredLogger, error) {
+func NewEnvironmentSugarLogger(environment string, pathsToLogs []string, opts ...Option) (*zap.SugaredLogger, error) {
 	switch strings.ToLower(environment) {
 	case Development:
-		return NewDevelopmentSugarLogger(pathsToLogs)
+		return NewDevelopmentSugarLogger(pathsToLogs, opts...)
 	case Production:
-		return NewProductionSugarLogger(pathsToLogs)
+		return NewProductionSugarLogger(pathsToLogs, opts...)
 	default:
-		return NewProductionSugarLogger(pathsToLogs)
+		return NewProductionSugarLogger(pathsToLogs, opts...)
 	}
 }
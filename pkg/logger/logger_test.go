@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNew(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+	core, logs := observer.New(zapcore.DebugLevel)
+	hooked := 0
+
+	encoder, err := EncoderPreset(FormatLogfmt)
+	if err != nil {
+		t.Fatalf("EncoderPreset() error = %v", err)
+	}
+	encoder.Encoding = EncodingJSON
+
+	handle, err := New(
+		WithEnvironment(Production),
+		WithLevel("warn"),
+		WithOutputs(logFile),
+		WithEncoder(encoder),
+		WithFields(zap.String("service", "test"), zap.String("version", "1.0.0")),
+		WithCores(core),
+		WithHooks(func(zapcore.Entry) error {
+			hooked++
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	handle.Sugar().Info("skipped")
+	handle.Sugar().Warnw("written", "key", "value")
+	_ = handle.Logger().Sync()
+
+	if got := handle.Level().Level(); got != zapcore.WarnLevel {
+		t.Errorf("Level() = %s, want warn", got)
+	}
+	// info entry is written by extra core only, but hooks are called for it too
+	if hooked != 2 {
+		t.Errorf("hooks called %d times, want 2", hooked)
+	}
+
+	// extra core uses its own level and gets initial fields
+	if logs.Len() != 2 || logs.FilterField(zap.String("service", "test")).Len() != 2 {
+		t.Errorf("extra core entries = %v", logs.All())
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("unable to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("log lines = %q, want 1 line", lines)
+	}
+
+	// logfmt keys with json encoding
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("unable to decode log line %s: %v", lines[0], err)
+	}
+	if entry["msg"] != "written" || entry["service"] != "test" || entry["version"] != "1.0.0" || entry["key"] != "value" {
+		t.Errorf("log entry = %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "logger/logger_test.go") {
+		t.Errorf("log entry caller = %v", entry["caller"])
+	}
+}
+
+func TestNewCallerSkip(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "log")
+
+	handle, err := New(WithOutputs(logFile), WithCallerSkip(1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// wrapper is skipped, so caller is a test function
+	logWrapper := func(message string) {
+		handle.Logger().Info(message)
+	}
+	logWrapper("wrapped")
+	_ = handle.Logger().Sync()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatalf("unable to read log: %v", err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("unable to decode log %s: %v", data, err)
+	}
+	if caller, _ := entry["caller"].(string); !strings.HasPrefix(caller, "logger/logger_test.go:") {
+		t.Errorf("log entry caller = %v", entry["caller"])
+	}
+}
+
+func TestNewDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantLevel   zapcore.Level
+		wantLimited bool
+	}{
+		{
+			name:      "without options",
+			wantLevel: zapcore.InfoLevel,
+		},
+		{
+			name:      "development",
+			opts:      []Option{WithDevelopment(), WithOutputs(stdout)},
+			wantLevel: zapcore.DebugLevel,
+		},
+		{
+			name:        "production environment",
+			opts:        []Option{WithEnvironment(Production)},
+			wantLevel:   zapcore.InfoLevel,
+			wantLimited: true,
+		},
+		{
+			name:      "production environment without limits",
+			opts:      []Option{WithEnvironment(Production), WithLimits(&Limits{})},
+			wantLevel: zapcore.InfoLevel,
+		},
+		{
+			name:      "level overrides environment",
+			opts:      []Option{WithLevel("error"), WithEnvironment(Development), WithOutputs(stdout)},
+			wantLevel: zapcore.ErrorLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle, err := New(tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if got := handle.Level().Level(); got != tt.wantLevel {
+				t.Errorf("Level() = %s, want %s", got, tt.wantLevel)
+			}
+			if _, limited := handle.Logger().Core().(*rateLimitCore); limited != tt.wantLimited {
+				t.Errorf("logger is limited = %v, want %v", limited, tt.wantLimited)
+			}
+		})
+	}
+}
+
+func TestFromContextFallback(t *testing.T) {
+	sugarLogger := FromContext(context.Background())
+	if sugarLogger != Fallback() {
+		t.Errorf("FromContext() without logger is not Fallback()")
+	}
+	// fallback logger writes to stderr instead of discarding entries like global zap logger
+	if !sugarLogger.Desugar().Core().Enabled(zapcore.InfoLevel) {
+		t.Errorf("Fallback() does not write info entries")
+	}
+
+	stored := zap.NewNop().Sugar()
+	if got := FromContext(WithContext(context.Background(), stored)); got != stored {
+		t.Errorf("FromContext() = %v, want stored logger", got)
+	}
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap/zapcore"
+)
+
+// metricsCore counts entries by their levels
+// entries are counted before sampling and rate limiting, so counter shows real rate of log messages
+type metricsCore struct {
+	zapcore.Core
+	counter *prometheus.CounterVec
+}
+
+// With implements zapcore.Core
+func (c *metricsCore) With(fields []zapcore.Field) zapcore.Core {
+	return &metricsCore{Core: c.Core.With(fields), counter: c.counter}
+}
+
+// Check implements zapcore.Core
+func (c *metricsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		c.counter.WithLabelValues(entry.Level.String()).Inc()
+	}
+
+	return c.Core.Check(entry, checked)
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/levinishka/scratch/pkg/metrics/logmetrics"
+)
+
+func TestMetrics(t *testing.T) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_log_messages_total"}, []string{"level"})
+	logFile := filepath.Join(t.TempDir(), "log")
+
+	handle, err := New(
+		WithOutputs(logFile),
+		WithLimits(&Limits{Sampling: map[string]Sampling{"info": {Initial: 1}}}),
+		WithMetrics(counter),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sugarLogger := handle.Sugar().With("key", "value")
+	for i := 0; i < 3; i++ {
+		sugarLogger.Debug("disabled")
+		sugarLogger.Info("sampled")
+	}
+	sugarLogger.Error("failed")
+
+	// sampled entries are counted too, disabled ones are not
+	for level, want := range map[zapcore.Level]float64{zapcore.DebugLevel: 0, zapcore.InfoLevel: 3, zapcore.ErrorLevel: 1} {
+		if got := testutil.ToFloat64(counter.WithLabelValues(level.String())); got != want {
+			t.Errorf("%s messages = %v, want %v", level, got, want)
+		}
+	}
+}
+
+func TestMetricsDefault(t *testing.T) {
+	infoMessages := logmetrics.MessagesTotal.WithLabelValues("info")
+	before := testutil.ToFloat64(infoMessages)
+
+	// messages are not counted without WithMetrics
+	sugarLogger, err := NewSugarLogger("info", []string{filepath.Join(t.TempDir(), "log")}, EncodingJSON)
+	if err != nil {
+		t.Fatalf("NewSugarLogger() error = %v", err)
+	}
+	sugarLogger.Info("not counted")
+
+	if got := testutil.ToFloat64(infoMessages) - before; got != 0 {
+		t.Errorf("info messages without metrics = %v, want 0", got)
+	}
+
+	handle, err := New(WithOutputs(filepath.Join(t.TempDir(), "log")), WithMetrics(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	handle.Logger().Info("counted")
+
+	if got := testutil.ToFloat64(infoMessages) - before; got != 1 {
+		t.Errorf("info messages = %v, want 1", got)
+	}
+}
+
+func TestMetricsLegacyConstructors(t *testing.T) {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_log_messages_total"}, []string{"level"})
+	logFile := filepath.Join(t.TempDir(), "log")
+
+	constructors := map[string]func() (*zap.SugaredLogger, error){
+		"sugar": func() (*zap.SugaredLogger, error) {
+			return NewSugarLogger("info", []string{logFile}, EncodingJSON, WithMetrics(counter))
+		},
+		"development": func() (*zap.SugaredLogger, error) {
+			return NewDevelopmentSugarLogger([]string{logFile}, WithMetrics(counter))
+		},
+		"environment": func() (*zap.SugaredLogger, error) {
+			return NewEnvironmentSugarLogger(Production, []string{logFile}, WithMetrics(counter))
+		},
+	}
+	for name, constructor := range constructors {
+		t.Run(name, func(t *testing.T) {
+			sugarLogger, err := constructor()
+			if err != nil {
+				t.Fatalf("constructor error = %v", err)
+			}
+
+			before := testutil.ToFloat64(counter.WithLabelValues("warn"))
+			sugarLogger.Warn("counted")
+			if got := testutil.ToFloat64(counter.WithLabelValues("warn")) - before; got != 1 {
+				t.Errorf("warn messages = %v, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Option configures logger created by New
+type Option func(*options)
+
+// options stores logger configuration
+type options struct {
+	environment string
+	level       string
+	pathsToLogs []string
+	encoder     *Encoder
+	limits      *Limits
+	async       *AsyncOptions
+	fields      []zap.Field
+	callerSkip  int
+	development bool
+	cores       []zapcore.Core
+	hooks       []func(zapcore.Entry) error
+	zapOptions  []zap.Option
+	// counter counts entries by levels if countMessages is set
+	counter       *prometheus.CounterVec
+	countMessages bool
+}
+
+// WithEnvironment sets environment preset: development or production
+// development preset uses debug level, console encoding, writes to stderr too and is not limited,
+// other environments use info level, json encoding and EnvironmentLimits
+// other options override preset values regardless of their order
+func WithEnvironment(environment string) Option {
+	return func(o *options) {
+		o.environment = environment
+	}
+}
+
+// WithLevel sets logger level by its name, unknown names mean info level
+func WithLevel(logLevel string) Option {
+	return func(o *options) {
+		o.level = logLevel
+	}
+}
+
+// WithOutputs adds paths where logger writes: files, rotate: URLs, stdout or stderr
+// stderr is used if paths are not set
+func WithOutputs(pathsToLogs ...string) Option {
+	return func(o *options) {
+		o.pathsToLogs = append(o.pathsToLogs, pathsToLogs...)
+	}
+}
+
+// WithEncoder sets encoder of entries: DefaultEncoder with json, console or logfmt encoding,
+// preset returned by EncoderPreset or custom one
+// development logger uses console encoding and other loggers use json encoding if encoder is not set
+func WithEncoder(encoder Encoder) Option {
+	return func(o *options) {
+		o.encoder = &encoder
+	}
+}
+
+// WithLimits sets sampling and rate limiting, empty Limits disable environment limits
+func WithLimits(limits *Limits) Option {
+	return func(o *options) {
+		o.limits = limits
+	}
+}
+
+// WithAsync enables asynchronous writing to outputs with AsyncWriteSyncer, nil disables it
+// buffered entries are written on Sync of logger, Handle.Close writes them and stops writer before exit
+func WithAsync(async *AsyncOptions) Option {
+	return func(o *options) {
+		o.async = async
+	}
+}
+
+// WithFields adds fields to every entry, e.g. service name, version or hostname
+func WithFields(fields ...zap.Field) Option {
+	return func(o *options) {
+		o.fields = append(o.fields, fields...)
+	}
+}
+
+// WithCallerSkip increases number of skipped callers, it is useful for logger wrappers
+func WithCallerSkip(skip int) Option {
+	return func(o *options) {
+		o.callerSkip += skip
+	}
+}
+
+// WithDevelopment enables development mode: DPanic entries panic, console encoding and debug level
+// are used by default and stderr is always added to outputs
+func WithDevelopment() Option {
+	return func(o *options) {
+		o.development = true
+	}
+}
+
+// WithCores adds cores which receive all entries in addition to outputs
+// cores use their own levels and are not sampled or rate limited
+func WithCores(cores ...zapcore.Core) Option {
+	return func(o *options) {
+		o.cores = append(o.cores, cores...)
+	}
+}
+
+// WithHooks adds hooks which are called for every written entry
+func WithHooks(hooks ...func(zapcore.Entry) error) Option {
+	return func(o *options) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// WithMetrics enables counting of log messages by levels with counter which has level label,
+// logmetrics.MessagesTotal is used if counter is nil
+// messages are not counted without this option
+func WithMetrics(counter *prometheus.CounterVec) Option {
+	return func(o *options) {
+		o.counter = counter
+		o.countMessages = true
+	}
+}
+
+// WithZapOptions adds zap options which are applied after all other options
+func WithZapOptions(zapOptions ...zap.Option) Option {
+	return func(o *options) {
+		o.zapOptions = append(o.zapOptions, zapOptions...)
+	}
+}
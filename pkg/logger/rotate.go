@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/levinishka/scratch/pkg/units"
+)
+
+// RotateScheme is a scheme of rotating file sink, it can be used in pathsToLogs:
+//
+//	rotate:///var/log/app.log?max_size=100MiB&max_age=168h&max_backups=5&compress=true
+//	rotate:logs/log?max_size=10MiB
+//
+// max_size is a size of file which triggers rotation, 100MiB by default
+// max_age is a duration for which rotated files are kept, it is rounded up to days
+// max_backups is a number of rotated files which are kept
+// compress enables gzip of rotated files, local_time uses local time in names of rotated files instead of UTC
+// rotated files are never removed if both max_age and max_backups are not set
+const RotateScheme = "rotate"
+
+// rotate sink parameters
+const (
+	rotateMaxSize    = "max_size"
+	rotateMaxAge     = "max_age"
+	rotateMaxBackups = "max_backups"
+	rotateCompress   = "compress"
+	rotateLocalTime  = "local_time"
+
+	defaultRotateMaxSize = 100 * units.MiB
+)
+
+func init() {
+	if err := zap.RegisterSink(RotateScheme, newRotateSink); err != nil {
+		panic(fmt.Sprintf("logger: unable to register %s sink: %v", RotateScheme, err))
+	}
+}
+
+// rotateFiles stores rotating files opened by sinks by their paths
+// the same file can be opened by several sinks, e.g. as output and error output of one logger,
+// and it must be rotated only once
+var rotateFiles = struct {
+	sync.Mutex
+	files map[string]*rotateFile
+}{files: make(map[string]*rotateFile)}
+
+// rotateFile is a rotating file shared by sinks
+type rotateFile struct {
+	*lumberjack.Logger
+	// references is a number of open sinks, guarded by rotateFiles mutex
+	references int
+}
+
+// rotateSink is a zap.Sink which writes to rotating file
+type rotateSink struct {
+	path string
+	file *rotateFile
+	once sync.Once
+}
+
+// newRotateSink creates rotateSink by u
+// if file is already opened by another sink, its rotation parameters are used
+func newRotateSink(u *url.URL) (zap.Sink, error) {
+	const fn = "logger.newRotateSink"
+
+	fileLogger, err := parseRotateURL(u)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid sink %q: %v", fn, u, err)
+	}
+
+	rotateFiles.Lock()
+	defer rotateFiles.Unlock()
+
+	file, ok := rotateFiles.files[fileLogger.Filename]
+	if !ok {
+		file = &rotateFile{Logger: fileLogger}
+		rotateFiles.files[fileLogger.Filename] = file
+	}
+	file.references++
+
+	return &rotateSink{path: fileLogger.Filename, file: file}, nil
+}
+
+// parseRotateURL returns rotating file configured by u
+func parseRotateURL(u *url.URL) (*lumberjack.Logger, error) {
+	// rotate:relative/path, rotate://relative/path and rotate:///absolute/path are supported
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+	if path == "" {
+		return nil, fmt.Errorf("file path is empty")
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileLogger := &lumberjack.Logger{Filename: absPath}
+
+	query := u.Query()
+	maxSize := defaultRotateMaxSize
+	if value := query.Get(rotateMaxSize); value != "" {
+		if maxSize, err = units.ParseByteSize(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", rotateMaxSize, err)
+		}
+	}
+	// lumberjack counts size in megabytes
+	fileLogger.MaxSize = int(math.Ceil(float64(maxSize) / float64(units.MiB)))
+
+	if value := query.Get(rotateMaxAge); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", rotateMaxAge, err)
+		}
+		fileLogger.MaxAge = int(math.Ceil(maxAge.Hours() / 24))
+	}
+
+	if value := query.Get(rotateMaxBackups); value != "" {
+		if fileLogger.MaxBackups, err = strconv.Atoi(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", rotateMaxBackups, err)
+		}
+	}
+
+	if value := query.Get(rotateCompress); value != "" {
+		if fileLogger.Compress, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", rotateCompress, err)
+		}
+	}
+
+	if value := query.Get(rotateLocalTime); value != "" {
+		if fileLogger.LocalTime, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%s: %v", rotateLocalTime, err)
+		}
+	}
+
+	return fileLogger, nil
+}
+
+// Write implements io.Writer
+func (s *rotateSink) Write(p []byte) (int, error) {
+	return s.file.Write(p)
+}
+
+// Sync implements zapcore.WriteSyncer
+// rotating file is not buffered, so there is nothing to flush
+func (s *rotateSink) Sync() error {
+	return nil
+}
+
+// Close implements io.Closer
+// file is closed when its last sink is closed
+func (s *rotateSink) Close() error {
+	var err error
+	s.once.Do(func() {
+		rotateFiles.Lock()
+		defer rotateFiles.Unlock()
+
+		s.file.references--
+		if s.file.references > 0 {
+			return
+		}
+		delete(rotateFiles.files, s.path)
+		err = s.file.Close()
+	})
+
+	return err
+}
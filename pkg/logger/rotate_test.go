@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRotateURL(t *testing.T) {
+	tests := []struct {
+		name           string
+		rawURL         string
+		wantPath       string
+		wantMaxSize    int
+		wantMaxAge     int
+		wantMaxBackups int
+		wantCompress   bool
+		wantErr        bool
+	}{
+		{
+			name:        "absolute path with defaults",
+			rawURL:      "rotate:///var/log/app.log",
+			wantPath:    "/var/log/app.log",
+			wantMaxSize: 100,
+		},
+		{
+			name:           "all parameters",
+			rawURL:         "rotate:///var/log/app.log?max_size=10MB&max_age=36h&max_backups=3&compress=true",
+			wantPath:       "/var/log/app.log",
+			wantMaxSize:    10,
+			wantMaxAge:     2,
+			wantMaxBackups: 3,
+			wantCompress:   true,
+		},
+		{
+			name:        "relative path",
+			rawURL:      "rotate:logs/log?max_size=1GiB",
+			wantPath:    "logs/log",
+			wantMaxSize: 1024,
+		},
+		{
+			name:    "empty path",
+			rawURL:  "rotate:",
+			wantErr: true,
+		},
+		{
+			name:    "invalid size",
+			rawURL:  "rotate:///var/log/app.log?max_size=big",
+			wantErr: true,
+		},
+		{
+			name:    "invalid compress",
+			rawURL:  "rotate:///var/log/app.log?compress=maybe",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.rawURL)
+			if err != nil {
+				t.Fatalf("url.Parse() error = %v", err)
+			}
+
+			got, err := parseRotateURL(u)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRotateURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			wantPath, _ := filepath.Abs(tt.wantPath)
+			if got.Filename != wantPath || got.MaxSize != tt.wantMaxSize || got.MaxAge != tt.wantMaxAge ||
+				got.MaxBackups != tt.wantMaxBackups || got.Compress != tt.wantCompress {
+				t.Errorf("parseRotateURL() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestRotateSink(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "app.log")
+
+	// the same path is used for output and error output, so file must be shared
+	loggerInstance, err := NewLogger("info", []string{"rotate://" + logFile + "?max_size=1MiB&compress=true"}, "json")
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	message := strings.Repeat("a", 1024)
+	for i := 0; i < 1500; i++ {
+		loggerInstance.Info(message)
+	}
+	_ = loggerInstance.Sync()
+
+	// rotated files are compressed in background
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		compressed, _ := filepath.Glob(filepath.Join(filepath.Dir(logFile), "app-*.log.gz"))
+		if len(compressed) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			entries, _ := os.ReadDir(filepath.Dir(logFile))
+			t.Fatalf("rotated file has not been compressed, files: %v", entries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	info, err := os.Stat(logFile)
+	if err != nil {
+		t.Fatalf("unable to stat log file: %v", err)
+	}
+	if info.Size() == 0 || info.Size() > 1024*1024 {
+		t.Errorf("log file size = %d", info.Size())
+	}
+}
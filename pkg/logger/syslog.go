@@ -0,0 +1,423 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// syslog sink schemes, they can be used in pathsToLogs:
+//
+//	syslog+udp://localhost:514?facility=local0&app_name=app
+//	syslog+tcp://logs.example.com:6514?severity=notice
+//	syslog+unix:///dev/log
+//
+// entries are written as RFC 5424 messages, every line is a separate message,
+// tcp and unix stream messages are framed with octet counting (RFC 6587)
+// facility is a name (kern, user, daemon, local0-local7...) or a number, user by default
+// severity of entries written by loggers from New is chosen by their level: debug, info, warning, err,
+// crit for dpanic, alert for panic and emerg for fatal, severity parameter sets one severity for all entries,
+// it is a name (emerg, alert, crit, err, warning, notice, info, debug) or a number,
+// sinks opened with zap.Open get encoded entries only, so they use info severity by default
+// app_name, hostname and msg_id are written to header, executable name and hostname are used by default
+// connection is established on the first write and reestablished after errors
+const (
+	SyslogUDPScheme  = "syslog+udp"
+	SyslogTCPScheme  = "syslog+tcp"
+	SyslogUnixScheme = "syslog+unix"
+)
+
+// syslog sink parameters
+const (
+	syslogFacility = "facility"
+	syslogSeverity = "severity"
+	syslogAppName  = "app_name"
+	syslogHostname = "hostname"
+	syslogMsgID    = "msg_id"
+
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 5 * time.Second
+	// syslogTimeFormat is RFC 3339 time with microseconds allowed by RFC 5424
+	syslogTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+	// syslogNilValue is written instead of empty header fields
+	syslogNilValue = "-"
+)
+
+// syslogFacilities stores facility codes by their names
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverities stores severity codes by their names
+var syslogSeverities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// syslogLevelSeverities stores severity codes by levels of entries
+var syslogLevelSeverities = map[zapcore.Level]int{
+	zapcore.DebugLevel:  syslogSeverities["debug"],
+	zapcore.InfoLevel:   syslogSeverities["info"],
+	zapcore.WarnLevel:   syslogSeverities["warning"],
+	zapcore.ErrorLevel:  syslogSeverities["err"],
+	zapcore.DPanicLevel: syslogSeverities["crit"],
+	zapcore.PanicLevel:  syslogSeverities["alert"],
+	zapcore.FatalLevel:  syslogSeverities["emerg"],
+}
+
+func init() {
+	for _, scheme := range []string{SyslogUDPScheme, SyslogTCPScheme, SyslogUnixScheme} {
+		if err := zap.RegisterSink(scheme, newSyslogSink); err != nil {
+			panic(fmt.Sprintf("logger: unable to register %s sink: %v", scheme, err))
+		}
+	}
+}
+
+// syslogSink is a zap.Sink which writes entries to syslog server
+type syslogSink struct {
+	network  string
+	address  string
+	facility int
+	severity int
+	// fixedSeverity reports whether severity is set by parameter and is used for entries of all levels
+	fixedSeverity bool
+	// tail is written after timestamp: hostname, app name, process id, message id and structured data
+	tail string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// newSyslogSink creates syslogSink by u
+func newSyslogSink(u *url.URL) (zap.Sink, error) {
+	const fn = "logger.newSyslogSink"
+
+	sink, err := parseSyslogURL(u)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid sink %q: %v", fn, u, err)
+	}
+
+	return sink, nil
+}
+
+// parseSyslogURL returns syslogSink configured by u
+func parseSyslogURL(u *url.URL) (*syslogSink, error) {
+	sink := &syslogSink{}
+
+	switch u.Scheme {
+	case SyslogUDPScheme, SyslogTCPScheme:
+		sink.network = strings.TrimPrefix(u.Scheme, "syslog+")
+		sink.address = u.Host
+	case SyslogUnixScheme:
+		sink.network = "unix"
+		sink.address = u.Opaque
+		if sink.address == "" {
+			sink.address = u.Host + u.Path
+		}
+	default:
+		return nil, fmt.Errorf("unknown scheme %s", u.Scheme)
+	}
+	if sink.address == "" {
+		return nil, fmt.Errorf("address is empty")
+	}
+
+	query := u.Query()
+	facility, err := parseSyslogCode(query.Get(syslogFacility), syslogFacilities, syslogFacilities["user"], 23)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", syslogFacility, err)
+	}
+	severity, err := parseSyslogCode(query.Get(syslogSeverity), syslogSeverities, syslogSeverities["info"], 7)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", syslogSeverity, err)
+	}
+	sink.facility = facility
+	sink.severity = severity
+	sink.fixedSeverity = query.Has(syslogSeverity)
+
+	appName := query.Get(syslogAppName)
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	hostname := query.Get(syslogHostname)
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	sink.tail = fmt.Sprintf(" %s %s %d %s - ",
+		syslogHeaderField(hostname, 255), syslogHeaderField(appName, 48), os.Getpid(), syslogHeaderField(query.Get(syslogMsgID), 32))
+
+	return sink, nil
+}
+
+// parseSyslogCode returns code by its name or number, defaultCode is returned for empty value
+func parseSyslogCode(value string, codes map[string]int, defaultCode int, maxCode int) (int, error) {
+	if value == "" {
+		return defaultCode, nil
+	}
+	if code, ok := codes[strings.ToLower(value)]; ok {
+		return code, nil
+	}
+
+	code, err := strconv.Atoi(value)
+	if err != nil || code < 0 || code > maxCode {
+		return 0, fmt.Errorf("unknown value %q", value)
+	}
+
+	return code, nil
+}
+
+// syslogHeaderField returns value which can be written to header: printable ASCII without spaces
+// truncated to maxLength, or nil value if it is empty
+func syslogHeaderField(value string, maxLength int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return -1
+		}
+		return r
+	}, value)
+	if len(field) > maxLength {
+		field = field[:maxLength]
+	}
+	if field == "" {
+		return syslogNilValue
+	}
+
+	return field
+}
+
+// Write implements io.Writer
+// every line of p is written as a separate message with severity of sink
+func (s *syslogSink) Write(p []byte) (int, error) {
+	if err := s.writeMessages(s.messages(s.severity, time.Now(), p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// entrySeverity returns severity of entries with level
+func (s *syslogSink) entrySeverity(level zapcore.Level) int {
+	if severity, ok := syslogLevelSeverities[level]; ok && !s.fixedSeverity {
+		return severity
+	}
+
+	return s.severity
+}
+
+// messages returns every line of p as a message with severity and timestamp t, messages are separated by new lines
+func (s *syslogSink) messages(severity int, t time.Time, p []byte) []byte {
+	header := fmt.Sprintf("<%d>1 %s%s", s.facility*8+severity, t.Format(syslogTimeFormat), s.tail)
+
+	var messages bytes.Buffer
+	for _, line := range bytes.Split(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		messages.WriteString(header)
+		messages.Write(line)
+		messages.WriteByte('\n')
+	}
+
+	return messages.Bytes()
+}
+
+// writeMessages writes every line of p as a message
+func (s *syslogSink) writeMessages(p []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, message := range bytes.Split(p, []byte("\n")) {
+		if len(message) == 0 {
+			continue
+		}
+		if err := s.writeMessage(string(message)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeMessage writes message to connection, connection is reestablished once if writing fails
+// messages of stream connections are prefixed with their length
+func (s *syslogSink) writeMessage(message string) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if err = s.connect(); err != nil {
+				continue
+			}
+		}
+
+		frame := message
+		if s.network != "udp" && s.network != "unixgram" {
+			frame = strconv.Itoa(len(message)) + " " + message
+		}
+
+		_ = s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+		if _, err = s.conn.Write([]byte(frame)); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+
+	return err
+}
+
+// connect establishes connection, unix sockets are tried as datagram and then as stream ones
+func (s *syslogSink) connect() error {
+	if s.network == "unix" || s.network == "unixgram" {
+		conn, err := net.DialTimeout("unixgram", s.address, syslogDialTimeout)
+		if err == nil {
+			s.network = "unixgram"
+			s.conn = conn
+			return nil
+		}
+		s.network = "unix"
+	}
+
+	conn, err := net.DialTimeout(s.network, s.address, syslogDialTimeout)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	return nil
+}
+
+// Sync implements zapcore.WriteSyncer
+// messages are not buffered, so there is nothing to flush
+func (s *syslogSink) Sync() error {
+	return nil
+}
+
+// Close implements io.Closer
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+
+	return err
+}
+
+// syslogMessageWriter is a zapcore.WriteSyncer which writes messages prepared by syslogSink.messages
+type syslogMessageWriter struct {
+	sink *syslogSink
+}
+
+// Write implements io.Writer
+func (w syslogMessageWriter) Write(p []byte) (int, error) {
+	if err := w.sink.writeMessages(p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Sync implements zapcore.WriteSyncer
+func (w syslogMessageWriter) Sync() error {
+	return w.sink.Sync()
+}
+
+// isSyslogPath reports whether path is written with syslog sink
+func isSyslogPath(path string) bool {
+	u, err := url.Parse(path)
+	if err != nil {
+		return false
+	}
+
+	switch u.Scheme {
+	case SyslogUDPScheme, SyslogTCPScheme, SyslogUnixScheme:
+		return true
+	default:
+		return false
+	}
+}
+
+// syslogCore is a zapcore.Core which writes entries to syslog sink with severity chosen by their level
+// sinks get encoded entries only, so syslog paths of New are written with their own cores
+type syslogCore struct {
+	zapcore.LevelEnabler
+	encoder zapcore.Encoder
+	sink    *syslogSink
+	output  zapcore.WriteSyncer
+}
+
+// newSyslogCore creates syslogCore which writes entries enabled by level with encoder to syslog path
+// messages are written asynchronously with async options if they are not nil
+// returned function stops asynchronous writer and closes connection
+func newSyslogCore(path string, encoder zapcore.Encoder, level zapcore.LevelEnabler, async *AsyncOptions) (zapcore.Core, func() error, error) {
+	u, err := url.Parse(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	sink, err := parseSyslogURL(u)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid sink %q: %v", path, err)
+	}
+
+	output, closeOutput := withAsync(syslogMessageWriter{sink: sink}, func() {
+		_ = sink.Close()
+	}, async)
+
+	return &syslogCore{LevelEnabler: level, encoder: encoder, sink: sink, output: output}, closeOutput, nil
+}
+
+// With implements zapcore.Core
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	return &syslogCore{LevelEnabler: c.LevelEnabler, encoder: encoder, sink: c.sink, output: c.output}
+}
+
+// Check implements zapcore.Core
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+// Write implements zapcore.Core
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buffer, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	defer buffer.Free()
+
+	if _, err := c.output.Write(c.sink.messages(c.sink.entrySeverity(entry.Level), entry.Time, buffer.Bytes())); err != nil {
+		return err
+	}
+	// entries of panic and fatal levels are flushed before process stops like in zapcore
+	if entry.Level > zapcore.ErrorLevel {
+		_ = c.Sync()
+	}
+
+	return nil
+}
+
+// Sync implements zapcore.Core
+func (c *syslogCore) Sync() error {
+	return c.output.Sync()
+}
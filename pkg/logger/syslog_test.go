@@ -0,0 +1,207 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syslogMessageRegexp matches RFC 5424 message written by syslog sink
+var syslogMessageRegexp = regexp.MustCompile(`^<(\d+)>1 \S+ (\S+) (\S+) (\d+) (\S+) - (.*)$`)
+
+func TestSyslogSink(t *testing.T) {
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen udp: %v", err)
+	}
+	defer udpConn.Close()
+
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen tcp: %v", err)
+	}
+	defer tcpListener.Close()
+
+	unixPath := filepath.Join(t.TempDir(), "log.sock")
+	unixConn, err := net.ListenPacket("unixgram", unixPath)
+	if err != nil {
+		t.Fatalf("unable to listen unixgram: %v", err)
+	}
+	defer unixConn.Close()
+
+	readPacket := func(conn net.PacketConn) func() (string, error) {
+		return func() (string, error) {
+			buffer := make([]byte, 65536)
+			_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			n, _, err := conn.ReadFrom(buffer)
+			return string(buffer[:n]), err
+		}
+	}
+
+	// tcp messages are framed with octet counting
+	tcpMessages := make(chan string, 10)
+	go func() {
+		conn, err := tcpListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			size, _ := strconv.Atoi(strings.TrimSpace(length))
+			message := make([]byte, size)
+			if _, err := reader.Read(message); err != nil {
+				return
+			}
+			tcpMessages <- string(message)
+		}
+	}()
+	readTCP := func() (string, error) {
+		select {
+		case message := <-tcpMessages:
+			return message, nil
+		case <-time.After(5 * time.Second):
+			return "", fmt.Errorf("timeout")
+		}
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		read         func() (string, error)
+		wantPriority int
+		wantAppName  string
+		wantMsgID    string
+	}{
+		{
+			name:         "udp",
+			path:         "syslog+udp://" + udpConn.LocalAddr().String() + "?facility=local0&severity=warning&app_name=app&msg_id=api",
+			read:         readPacket(udpConn),
+			wantPriority: 16*8 + 4,
+			wantAppName:  "app",
+			wantMsgID:    "api",
+		},
+		{
+			name:         "tcp",
+			path:         "syslog+tcp://" + tcpListener.Addr().String() + "?facility=3",
+			read:         readTCP,
+			wantPriority: 3*8 + 6,
+			wantAppName:  filepath.Base(os.Args[0]),
+			wantMsgID:    "-",
+		},
+		{
+			name:         "unix",
+			path:         "syslog+unix://" + unixPath,
+			read:         readPacket(unixConn),
+			wantPriority: 1*8 + 6,
+			wantAppName:  filepath.Base(os.Args[0]),
+			wantMsgID:    "-",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handle, err := New(WithOutputs(tt.path), WithEncoder(DefaultEncoder(EncodingLogfmt)))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			handle.Sugar().Infow("first message", "key", "value")
+			handle.Sugar().Info("second message")
+
+			for _, want := range []string{"first message", "second message"} {
+				message, err := tt.read()
+				if err != nil {
+					t.Fatalf("unable to read message: %v", err)
+				}
+
+				match := syslogMessageRegexp.FindStringSubmatch(message)
+				if match == nil {
+					t.Fatalf("message %q is not RFC 5424 one", message)
+				}
+				if match[1] != strconv.Itoa(tt.wantPriority) || match[3] != tt.wantAppName ||
+					match[4] != strconv.Itoa(os.Getpid()) || match[5] != tt.wantMsgID {
+					t.Errorf("message header = %q", message)
+				}
+				if !strings.Contains(match[6], want) {
+					t.Errorf("message = %q, want to contain %q", match[6], want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyslogSinkInvalid(t *testing.T) {
+	for _, path := range []string{
+		"syslog+udp://",
+		"syslog+udp://localhost:514?facility=unknown",
+		"syslog+tcp://localhost:514?severity=8",
+	} {
+		u, err := url.Parse(path)
+		if err != nil {
+			t.Fatalf("unable to parse %s: %v", path, err)
+		}
+		if _, err := newSyslogSink(u); err == nil {
+			t.Errorf("newSyslogSink(%s) error = nil", path)
+		}
+	}
+}
+
+func TestSyslogSinkEntrySeverity(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"sync", nil},
+		{"async", []Option{WithAsync(&AsyncOptions{})}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("unable to listen udp: %v", err)
+			}
+			defer udpConn.Close()
+
+			handle, err := New(append([]Option{
+				WithOutputs("syslog+udp://" + udpConn.LocalAddr().String() + "?facility=local0"),
+				WithEncoder(DefaultEncoder(EncodingLogfmt)),
+			}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer handle.Close()
+
+			handle.Sugar().Warn("warn message")
+			handle.Sugar().Error("error message")
+			handle.Sugar().Info("info message")
+			_ = handle.Logger().Sync()
+
+			for _, wantPriority := range []int{16*8 + 4, 16*8 + 3, 16*8 + 6} {
+				buffer := make([]byte, 65536)
+				_ = udpConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+				n, _, err := udpConn.ReadFrom(buffer)
+				if err != nil {
+					t.Fatalf("unable to read message: %v", err)
+				}
+
+				match := syslogMessageRegexp.FindStringSubmatch(string(buffer[:n]))
+				if match == nil || match[1] != strconv.Itoa(wantPriority) {
+					t.Errorf("message %q, want priority %d", buffer[:n], wantPriority)
+				}
+			}
+		})
+	}
+}
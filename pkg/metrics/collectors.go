@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"runtime"
+	"runtime/debug"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Version and Commit describe deployed build, they can be set with ldflags:
+//
+//	go build -ldflags "-X github.com/levinishka/scratch/pkg/metrics.Version=v1.2.3 -X github.com/levinishka/scratch/pkg/metrics.Commit=0a1b2c3"
+//
+// version of main module and vcs revision of build info are used if they are empty,
+// vcs revision is used as version of development builds which have "(devel)" version
+var (
+	Version string
+	Commit  string
+)
+
+const (
+	// unknownBuildValue is used for build info values which can not be read
+	unknownBuildValue = "unknown"
+	// develVersion is a version of main module built from working tree, e.g. with go build or go run
+	develVersion = "(devel)"
+)
+
+// startTime is a time when application has been started
+var startTime = time.Now()
+
+// BuildInfo describes build of application
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	GoVersion string
+	// Path is a path of main module
+	Path string
+}
+
+// ReadBuildInfo returns BuildInfo from Version, Commit and debug.ReadBuildInfo
+func ReadBuildInfo() BuildInfo {
+	buildInfo, _ := debug.ReadBuildInfo()
+	return newBuildInfo(Version, Commit, buildInfo)
+}
+
+// newBuildInfo returns BuildInfo from version, commit and buildInfo which can be nil
+func newBuildInfo(version string, commit string, buildInfo *debug.BuildInfo) BuildInfo {
+	info := BuildInfo{
+		Version:   version,
+		Commit:    commit,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo != nil {
+		info.Path = buildInfo.Main.Path
+		var revision string
+		for _, setting := range buildInfo.Settings {
+			if setting.Key == "vcs.revision" {
+				revision = setting.Value
+			}
+		}
+
+		if info.Commit == "" {
+			info.Commit = revision
+		}
+		if info.Version == "" {
+			info.Version = buildInfo.Main.Version
+		}
+		if info.Version == develVersion {
+			info.Version = revision
+		}
+	}
+
+	for _, value := range []*string{&info.Version, &info.Commit, &info.Path} {
+		if *value == "" {
+			*value = unknownBuildValue
+		}
+	}
+
+	return info
+}
+
+// newRuntimeCollectors returns collectors of go runtime based on runtime/metrics and of process
+func newRuntimeCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(
+			collectors.MetricsGC,
+			collectors.MetricsMemory,
+			collectors.MetricsScheduler,
+		)),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	}
+}
+
+// newBuildCollectors returns build_info gauge with build labels and app_start_time_seconds gauge
+func (r *Registry) newBuildCollectors() []prometheus.Collector {
+	info := ReadBuildInfo()
+
+	buildLabels := prometheus.Labels{}
+	for name, value := range r.constLabels {
+		buildLabels[name] = value
+	}
+	buildLabels["version"] = info.Version
+	buildLabels["commit"] = info.Commit
+	buildLabels["go_version"] = info.GoVersion
+	buildLabels["path"] = info.Path
+
+	buildInfo := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   r.namespace,
+		Name:        "build_info",
+		Help:        "Build of application, value is always 1.",
+		ConstLabels: buildLabels,
+	})
+	buildInfo.Set(1)
+
+	appStartTime := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   r.namespace,
+		Name:        "app_start_time_seconds",
+		Help:        "Start time of application since unix epoch in seconds.",
+		ConstLabels: r.constLabels,
+	})
+	appStartTime.Set(float64(startTime.UnixNano()) / float64(time.Second))
+
+	return []prometheus.Collector{buildInfo, appStartTime}
+}
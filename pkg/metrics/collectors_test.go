@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegistryCollectors(t *testing.T) {
+	version, commit := Version, Commit
+	Version, Commit = "v1.2.3", "0a1b2c3"
+	defer func() {
+		Version, Commit = version, commit
+	}()
+
+	registry := NewRegistry(WithNamespace("svc"), WithConstLabels(prometheus.Labels{"env": "test"}))
+	families, err := registry.Gatherer().Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+
+	gathered := make(map[string]bool)
+	for _, family := range families {
+		gathered[family.GetName()] = true
+
+		switch family.GetName() {
+		case "svc_build_info":
+			labels := make(map[string]string)
+			for _, label := range family.GetMetric()[0].GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["version"] != "v1.2.3" || labels["commit"] != "0a1b2c3" ||
+				labels["go_version"] != runtime.Version() || labels["env"] != "test" || labels["path"] == "" {
+				t.Errorf("build_info labels = %v", labels)
+			}
+		case "svc_app_start_time_seconds":
+			started := time.Unix(0, int64(family.GetMetric()[0].GetGauge().GetValue()*float64(time.Second)))
+			if started.After(time.Now()) || time.Since(started) > time.Hour {
+				t.Errorf("app start time = %v", started)
+			}
+		}
+	}
+
+	for _, name := range []string{
+		"svc_build_info",
+		"svc_app_start_time_seconds",
+		"go_goroutines",
+		// runtime/metrics based series
+		"go_gc_gogc_percent",
+		"go_sched_gomaxprocs_threads",
+		"process_start_time_seconds",
+	} {
+		if !gathered[name] {
+			t.Errorf("%s is not gathered", name)
+		}
+	}
+}
+
+func TestNewBuildInfo(t *testing.T) {
+	withRevision := []debug.BuildSetting{{Key: "vcs.revision", Value: "0a1b2c3"}}
+
+	tests := []struct {
+		name        string
+		version     string
+		commit      string
+		buildInfo   *debug.BuildInfo
+		wantVersion string
+		wantCommit  string
+	}{
+		{"ldflags", "v1.2.3", "4d5e6f7", &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}, Settings: withRevision}, "v1.2.3", "4d5e6f7"},
+		{"module version", "", "", &debug.BuildInfo{Main: debug.Module{Version: "v1.0.0"}, Settings: withRevision}, "v1.0.0", "0a1b2c3"},
+		{"devel with revision", "", "", &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}, Settings: withRevision}, "0a1b2c3", "0a1b2c3"},
+		{"devel without revision", "", "", &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, "unknown", "unknown"},
+		{"without build info", "", "", nil, "unknown", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := newBuildInfo(tt.version, tt.commit, tt.buildInfo)
+			if info.Version != tt.wantVersion || info.Commit != tt.wantCommit {
+				t.Errorf("newBuildInfo() version = %q, commit = %q, want %q, %q", info.Version, info.Commit, tt.wantVersion, tt.wantCommit)
+			}
+		})
+	}
+}
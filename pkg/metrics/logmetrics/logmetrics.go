@@ -0,0 +1,25 @@
+// Package logmetrics provides log metrics which are shared by logger and metrics packages
+// metrics are only created here, so importing logger doesn't register anything in prometheus default registry,
+// they are registered in every metrics.Registry
+package logmetrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MessagesTotal counts log messages by level
+var MessagesTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "log_messages_total",
+		Help: "Number of log messages by level.",
+	},
+	[]string{"level"},
+)
+
+// DroppedEntriesTotal counts log entries dropped by asynchronous writers and shipping sinks
+var DroppedEntriesTotal = prometheus.NewCounter(
+	prometheus.CounterOpts{
+		Name: "log_dropped_entries_total",
+		Help: "Number of log entries dropped by asynchronous writers and shipping sinks.",
+	},
+)
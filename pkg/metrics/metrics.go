@@ -1,30 +1,20 @@
 package metrics
 
 import (
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/levinishka/scratch/pkg/metrics/logmetrics"
 )
 
-var HttpRequestsTotal = promauto.NewCounterVec(
-	prometheus.CounterOpts{
-		Name: "http_requests_total",
-		Help: "Number of HTTP requests.",
-	},
-	[]string{"path"},
+// http metrics of Default registry, use Registry to create them with namespace and const labels
+var (
+	HttpRequestsTotal           = Default.httpRequestsTotal
+	HttpRequestsDurationSeconds = Default.httpRequestsDurationSeconds
+	HttpRequestsInFlight        = Default.httpRequestsInFlight
+	HttpRequestSizeBytes        = Default.httpRequestSizeBytes
+	HttpResponseSizeBytes       = Default.httpResponseSizeBytes
 )
 
-var HttpRequestsDurationSeconds = promauto.NewHistogramVec(
-	prometheus.HistogramOpts{
-		Name: "http_requests_duration_seconds",
-		Help: "Duration of HTTP requests.",
-	},
-	[]string{"path"},
-)
-
-var HttpResponseStatusCodesTotal = promauto.NewCounterVec(
-	prometheus.CounterOpts{
-		Name: "http_response_status_codes_total",
-		Help: "Number of response status codes for path",
-	},
-	[]string{"path", "code"},
+// log metrics which are registered in every Registry, see logmetrics package
+var (
+	LogMessagesTotal       = logmetrics.MessagesTotal
+	LogDroppedEntriesTotal = logmetrics.DroppedEntriesTotal
 )
@@ -0,0 +1,337 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/urfave/negroni"
+)
+
+// Default is a Registry of prometheus default registry, it is used by PrometheusMiddleware and RunMetricsServer
+// go and process collectors are registered in default registry by prometheus
+var Default = newRegistry(prometheus.DefaultRegisterer, prometheus.DefaultGatherer)
+
+// synthetic path labels, so unknown paths do not increase cardinality of metrics
+const (
+	// UnmatchedRoute is a path label of requests which do not match any route,
+	// e.g. when Middleware is used without gorilla/mux
+	UnmatchedRoute = "unmatched"
+	// NotFoundRoute is a path label of requests served by not found handler of router
+	NotFoundRoute = "not_found"
+	// MethodNotAllowedRoute is a path label of requests served by method not allowed handler of router
+	MethodNotAllowedRoute = "method_not_allowed"
+)
+
+// otherMethod is a method label of requests with non-standard methods
+const otherMethod = "OTHER"
+
+// standardMethods stores methods which are used as method label
+var standardMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+// DefaultSizeBuckets are buckets of request and response size histograms: from 100B to 100MB
+var DefaultSizeBuckets = prometheus.ExponentialBuckets(100, 10, 7)
+
+// RegistryOption configures Registry created by NewRegistry
+type RegistryOption func(*Registry)
+
+// WithNamespace sets namespace of http metrics, e.g. service name
+func WithNamespace(namespace string) RegistryOption {
+	return func(r *Registry) {
+		r.namespace = namespace
+	}
+}
+
+// WithSubsystem sets subsystem of http metrics
+func WithSubsystem(subsystem string) RegistryOption {
+	return func(r *Registry) {
+		r.subsystem = subsystem
+	}
+}
+
+// WithConstLabels sets labels which are added to all http metrics, e.g. service version
+func WithConstLabels(constLabels prometheus.Labels) RegistryOption {
+	return func(r *Registry) {
+		r.constLabels = constLabels
+	}
+}
+
+// WithDurationBuckets sets buckets of request duration histogram, prometheus.DefBuckets are used by default
+func WithDurationBuckets(buckets ...float64) RegistryOption {
+	return func(r *Registry) {
+		r.durationBuckets = buckets
+	}
+}
+
+// WithSizeBuckets sets buckets of request and response size histograms, DefaultSizeBuckets are used by default
+func WithSizeBuckets(buckets ...float64) RegistryOption {
+	return func(r *Registry) {
+		r.sizeBuckets = buckets
+	}
+}
+
+// WithNativeHistograms enables native histograms with bucketFactor, e.g. 1.1, in addition to classic buckets
+// native histograms are scraped by prometheus with native histograms feature enabled only
+func WithNativeHistograms(bucketFactor float64) RegistryOption {
+	return func(r *Registry) {
+		r.nativeBucketFactor = bucketFactor
+	}
+}
+
+// WithStatusClasses uses status classes (2xx, 4xx...) as code label instead of status codes
+func WithStatusClasses() RegistryOption {
+	return func(r *Registry) {
+		r.statusClasses = true
+	}
+}
+
+// Registry owns prometheus registry and http metrics registered in it:
+// requests, their durations by method, path and code, requests in flight and sizes of requests and responses
+// log metrics (LogMessagesTotal and LogDroppedEntriesTotal), build_info and app_start_time_seconds
+// are registered in every Registry
+type Registry struct {
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+
+	namespace          string
+	subsystem          string
+	constLabels        prometheus.Labels
+	durationBuckets    []float64
+	sizeBuckets        []float64
+	nativeBucketFactor float64
+	statusClasses      bool
+
+	httpRequestsTotal           *prometheus.CounterVec
+	httpRequestsDurationSeconds *prometheus.HistogramVec
+	httpRequestsInFlight        *prometheus.GaugeVec
+	httpRequestSizeBytes        *prometheus.HistogramVec
+	httpResponseSizeBytes       *prometheus.HistogramVec
+}
+
+// NewRegistry creates Registry with its own prometheus registry
+// several registries can be used in one process, e.g. in tests or for several routers
+// go runtime collector based on runtime/metrics and process collector are registered in it
+func NewRegistry(opts ...RegistryOption) *Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newRuntimeCollectors()...)
+
+	return newRegistry(registry, registry, opts...)
+}
+
+// newRegistry creates Registry which registers metrics in registerer and gathers them from gatherer
+func newRegistry(registerer prometheus.Registerer, gatherer prometheus.Gatherer, opts ...RegistryOption) *Registry {
+	r := &Registry{
+		registerer:      registerer,
+		gatherer:        gatherer,
+		durationBuckets: prometheus.DefBuckets,
+		sizeBuckets:     DefaultSizeBuckets,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.httpRequestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   r.namespace,
+			Subsystem:   r.subsystem,
+			Name:        "http_requests_total",
+			Help:        "Number of HTTP requests.",
+			ConstLabels: r.constLabels,
+		},
+		[]string{"method", "path", "code"},
+	)
+	r.httpRequestsDurationSeconds = prometheus.NewHistogramVec(
+		r.histogramOpts("http_requests_duration_seconds", "Duration of HTTP requests.", r.durationBuckets),
+		[]string{"method", "path", "code"},
+	)
+	r.httpRequestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   r.namespace,
+			Subsystem:   r.subsystem,
+			Name:        "http_requests_in_flight",
+			Help:        "Number of HTTP requests which are being served.",
+			ConstLabels: r.constLabels,
+		},
+		[]string{"method", "path"},
+	)
+	r.httpRequestSizeBytes = prometheus.NewHistogramVec(
+		r.histogramOpts("http_request_size_bytes", "Size of HTTP request bodies.", r.sizeBuckets),
+		[]string{"method", "path"},
+	)
+	r.httpResponseSizeBytes = prometheus.NewHistogramVec(
+		r.histogramOpts("http_response_size_bytes", "Size of HTTP response bodies.", r.sizeBuckets),
+		[]string{"method", "path"},
+	)
+
+	r.MustRegister(
+		r.httpRequestsTotal,
+		r.httpRequestsDurationSeconds,
+		r.httpRequestsInFlight,
+		r.httpRequestSizeBytes,
+		r.httpResponseSizeBytes,
+		LogMessagesTotal,
+		LogDroppedEntriesTotal,
+	)
+	r.MustRegister(r.newBuildCollectors()...)
+
+	return r
+}
+
+// histogramOpts returns options of histogram with name, help and buckets
+func (r *Registry) histogramOpts(name string, help string, buckets []float64) prometheus.HistogramOpts {
+	opts := prometheus.HistogramOpts{
+		Namespace:   r.namespace,
+		Subsystem:   r.subsystem,
+		Name:        name,
+		Help:        help,
+		ConstLabels: r.constLabels,
+		Buckets:     buckets,
+	}
+	if r.nativeBucketFactor > 1 {
+		opts.NativeHistogramBucketFactor = r.nativeBucketFactor
+		opts.NativeHistogramMaxBucketNumber = 160
+		opts.NativeHistogramMinResetDuration = time.Hour
+	}
+
+	return opts
+}
+
+// Registerer returns prometheus registerer of Registry, use it to register own metrics,
+// e.g. with promauto.With(registry.Registerer())
+func (r *Registry) Registerer() prometheus.Registerer {
+	return r.registerer
+}
+
+// Gatherer returns prometheus gatherer of Registry
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	return r.gatherer
+}
+
+// MustRegister registers collectors and panics on errors
+func (r *Registry) MustRegister(collectors ...prometheus.Collector) {
+	r.registerer.MustRegister(collectors...)
+}
+
+// Handler returns http handler which serves metrics of Registry
+func (r *Registry) Handler() http.Handler {
+	return promhttp.InstrumentMetricHandler(r.registerer, promhttp.HandlerFor(r.gatherer, promhttp.HandlerOpts{}))
+}
+
+// Middleware adds http metrics to all http requests
+// path label is a path template of gorilla/mux route,
+// requests which do not match any route, e.g. on http.ServeMux, are counted with UnmatchedRoute path
+func (r *Registry) Middleware(nextHandler http.Handler) http.Handler {
+	return r.instrument(nextHandler, routeLabel)
+}
+
+// RouteHandler adds http metrics with route path label to handler, e.g. NotFoundRoute to not found handler of router
+func (r *Registry) RouteHandler(route string, handler http.Handler) http.Handler {
+	return r.instrument(handler, func(*http.Request) string {
+		return route
+	})
+}
+
+// instrument adds http metrics to nextHandler, path label is returned by pathLabel
+func (r *Registry) instrument(nextHandler http.Handler, pathLabel func(*http.Request) string) http.Handler {
+	return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		start := time.Now()
+		method := methodLabel(request.Method)
+		path := pathLabel(request)
+
+		inFlight := r.httpRequestsInFlight.WithLabelValues(method, path)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		// count size of request body if it is unknown
+		var body *countingReader
+		if request.ContentLength < 0 && request.Body != nil && request.Body != http.NoBody {
+			body = &countingReader{ReadCloser: request.Body}
+			request.Body = body
+		}
+
+		// create custom response writer to get response status code and size
+		newResponseWriter := negroni.NewResponseWriter(responseWriter)
+		nextHandler.ServeHTTP(newResponseWriter, request)
+
+		code := r.codeLabel(newResponseWriter.Status())
+		r.httpRequestsTotal.WithLabelValues(method, path, code).Inc()
+		r.httpRequestsDurationSeconds.WithLabelValues(method, path, code).Observe(time.Since(start).Seconds())
+
+		var requestSize int64
+		switch {
+		case body != nil:
+			requestSize = body.size
+		case request.ContentLength > 0:
+			requestSize = request.ContentLength
+		}
+		r.httpRequestSizeBytes.WithLabelValues(method, path).Observe(float64(requestSize))
+		r.httpResponseSizeBytes.WithLabelValues(method, path).Observe(float64(newResponseWriter.Size()))
+	})
+}
+
+// codeLabel returns code label of status, 0 status means that handler has not written anything, so it is 200
+func (r *Registry) codeLabel(status int) string {
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if r.statusClasses {
+		return strconv.Itoa(status/100) + "xx"
+	}
+
+	return strconv.Itoa(status)
+}
+
+// methodLabel returns method label, non-standard methods are collapsed to one label
+func methodLabel(method string) string {
+	if standardMethods[method] {
+		return method
+	}
+
+	return otherMethod
+}
+
+// RoutePath returns path template of gorilla/mux route matched by request
+// second value is false if request is served without mux router or by its not found handlers,
+// gorilla/mux stores nil route in their context
+func RoutePath(request *http.Request) (string, bool) {
+	route := mux.CurrentRoute(request)
+	if route == nil {
+		return "", false
+	}
+	path, err := route.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+
+	return path, true
+}
+
+// routeLabel returns path template of route matched by request or UnmatchedRoute
+func routeLabel(request *http.Request) string {
+	if path, ok := RoutePath(request); ok {
+		return path
+	}
+
+	return UnmatchedRoute
+}
+
+// countingReader counts bytes read from request body
+type countingReader struct {
+	io.ReadCloser
+	size int64
+}
+
+// Read implements io.Reader
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.size += int64(n)
+
+	return n, err
+}
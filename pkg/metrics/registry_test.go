@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRegistry(t *testing.T) {
+	// registries are independent, so the same metrics can be created twice
+	registry := NewRegistry(WithNamespace("app"), WithSubsystem("api"), WithConstLabels(prometheus.Labels{"version": "1.0.0"}))
+	otherRegistry := NewRegistry(WithNamespace("app"), WithSubsystem("api"))
+
+	router := mux.NewRouter()
+	router.Use(registry.Middleware)
+	router.HandleFunc("/users/{id}", func(respWriter http.ResponseWriter, req *http.Request) {
+		respWriter.WriteHeader(http.StatusNotFound)
+	})
+
+	for i := 0; i < 3; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	}
+
+	if got := testutil.ToFloat64(registry.httpRequestsTotal.WithLabelValues(http.MethodGet, "/users/{id}", "404")); got != 3 {
+		t.Errorf("requests = %v, want 3", got)
+	}
+	if got := testutil.CollectAndCount(otherRegistry.httpRequestsTotal); got != 0 {
+		t.Errorf("requests of other registry = %v, want 0", got)
+	}
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	for _, want := range []string{
+		`app_api_http_requests_total{code="404",method="GET",path="/users/{id}",version="1.0.0"} 3`,
+		`# TYPE log_dropped_entries_total counter`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics do not contain %s:\n%s", want, body)
+		}
+	}
+}
+
+func TestRegistryMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             []RegistryOption
+		method           string
+		body             io.Reader
+		handler          http.HandlerFunc
+		withoutRouter    bool
+		wantLabels       []string
+		wantRequestSize  float64
+		wantResponseSize float64
+	}{
+		{
+			name:   "request and response sizes",
+			method: http.MethodPost,
+			body:   strings.NewReader("request"),
+			handler: func(respWriter http.ResponseWriter, req *http.Request) {
+				_, _ = io.Copy(respWriter, req.Body)
+				_, _ = respWriter.Write([]byte(" response"))
+			},
+			wantLabels:       []string{http.MethodPost, "/test", "200"},
+			wantRequestSize:  7,
+			wantResponseSize: 16,
+		},
+		{
+			name:   "unknown request size",
+			method: http.MethodPut,
+			body:   io.MultiReader(strings.NewReader("chunked")),
+			handler: func(respWriter http.ResponseWriter, req *http.Request) {
+				_, _ = io.ReadAll(req.Body)
+			},
+			wantLabels:      []string{http.MethodPut, "/test", "200"},
+			wantRequestSize: 7,
+		},
+		{
+			name:   "status classes",
+			opts:   []RegistryOption{WithStatusClasses()},
+			method: http.MethodGet,
+			handler: func(respWriter http.ResponseWriter, req *http.Request) {
+				respWriter.WriteHeader(http.StatusServiceUnavailable)
+			},
+			wantLabels: []string{http.MethodGet, "/test", "5xx"},
+		},
+		{
+			name:       "non-standard method",
+			method:     "PURGE",
+			handler:    func(respWriter http.ResponseWriter, req *http.Request) {},
+			wantLabels: []string{otherMethod, "/test", "200"},
+		},
+		{
+			name:          "without router",
+			method:        http.MethodGet,
+			handler:       func(respWriter http.ResponseWriter, req *http.Request) {},
+			withoutRouter: true,
+			wantLabels:    []string{http.MethodGet, UnmatchedRoute, "200"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(tt.opts...)
+
+			var handler http.Handler = registry.Middleware(tt.handler)
+			if !tt.withoutRouter {
+				router := mux.NewRouter()
+				router.Use(registry.Middleware)
+				router.Handle("/test", tt.handler)
+				handler = router
+			}
+
+			request := httptest.NewRequest(tt.method, "/test", tt.body)
+			if _, ok := tt.body.(*strings.Reader); !ok {
+				request.ContentLength = -1
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			if got := testutil.ToFloat64(registry.httpRequestsTotal.WithLabelValues(tt.wantLabels...)); got != 1 {
+				t.Errorf("requests with labels %v = %v, want 1", tt.wantLabels, got)
+			}
+			if got := testutil.ToFloat64(registry.httpRequestsInFlight.WithLabelValues(tt.wantLabels[:2]...)); got != 0 {
+				t.Errorf("requests in flight = %v, want 0", got)
+			}
+
+			requestSize := registry.httpRequestSizeBytes.WithLabelValues(tt.wantLabels[:2]...).(prometheus.Histogram)
+			responseSize := registry.httpResponseSizeBytes.WithLabelValues(tt.wantLabels[:2]...).(prometheus.Histogram)
+			if got := histogramSum(t, requestSize); got != tt.wantRequestSize {
+				t.Errorf("request size = %v, want %v", got, tt.wantRequestSize)
+			}
+			if got := histogramSum(t, responseSize); got != tt.wantResponseSize {
+				t.Errorf("response size = %v, want %v", got, tt.wantResponseSize)
+			}
+		})
+	}
+}
+
+func TestRegistryNativeHistograms(t *testing.T) {
+	registry := NewRegistry(WithNativeHistograms(1.1), WithDurationBuckets(0.1, 1))
+	registry.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})).
+		ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	families, err := registry.Gatherer().Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != "http_requests_duration_seconds" {
+			continue
+		}
+		histogram := family.GetMetric()[0].GetHistogram()
+		if histogram.GetSchema() == 0 && histogram.GetZeroThreshold() == 0 {
+			t.Errorf("duration histogram is not native: %v", histogram)
+		}
+		if len(histogram.GetBucket()) != 2 {
+			t.Errorf("duration histogram buckets = %v, want 2", histogram.GetBucket())
+		}
+		return
+	}
+	t.Errorf("duration histogram is not gathered")
+}
+
+// histogramSum returns sum of observations of histogram
+func histogramSum(t *testing.T, histogram prometheus.Histogram) float64 {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(histogram)
+	families, err := registry.Gather()
+	if err != nil || len(families) != 1 {
+		t.Fatalf("unable to gather histogram: %v", err)
+	}
+
+	return families[0].GetMetric()[0].GetHistogram().GetSampleSum()
+}
+
+func TestRoutePath(t *testing.T) {
+	var (
+		gotPath string
+		gotOK   bool
+	)
+	handler := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotPath, gotOK = RoutePath(r)
+	})
+
+	router := mux.NewRouter()
+	router.Handle("/users/{id}", handler)
+	router.NotFoundHandler = handler
+
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		path     string
+		wantPath string
+		wantOK   bool
+	}{
+		{"matched", router, "/users/1", "/users/{id}", true},
+		{"not found", router, "/unknown", "", false},
+		{"without mux", handler, "/users/1", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if gotPath != tt.wantPath || gotOK != tt.wantOK {
+				t.Errorf("RoutePath() = %q, %v, want %q, %v", gotPath, gotOK, tt.wantPath, tt.wantOK)
+			}
+		})
+	}
+}
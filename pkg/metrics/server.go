@@ -1,42 +1,289 @@
 package metrics
 
 import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
-	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/gorilla/mux"
-	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"github.com/urfave/negroni"
+	"go.uber.org/zap"
 )
 
-const prometheusMetricsPath = "/metrics"
+// MetricsPath is a path of prometheus metrics handler
+const MetricsPath = "/metrics"
 
-// PrometheusMiddleware adds basic metrics to all http requests
+const (
+	defaultShutdownTimeout = 5 * time.Second
+	// readHeaderTimeout protects metrics server from slow clients
+	readHeaderTimeout = 10 * time.Second
+)
+
+// PrometheusMiddleware adds basic metrics of Default registry to all http requests
 func PrometheusMiddleware(nextHandler http.Handler) http.Handler {
-	return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
-		// increase total requests metrics
-		route := mux.CurrentRoute(request)
-		path, _ := route.GetPathTemplate()
-		HttpRequestsTotal.WithLabelValues(path).Inc()
-
-		// create custom response writer to get response status code
-		newResponseWriter := negroni.NewResponseWriter(responseWriter)
-
-		// count request processing time
-		timer := prometheus.NewTimer(HttpRequestsDurationSeconds.WithLabelValues(path))
-		nextHandler.ServeHTTP(newResponseWriter, request)
-		timer.ObserveDuration()
-
-		// count status codes
-		responseStatusCode := strconv.Itoa(newResponseWriter.Status())
-		HttpResponseStatusCodesTotal.WithLabelValues(path, responseStatusCode).Inc()
-	})
+	return Default.Middleware(nextHandler)
 }
 
-// RunMetricsServer runs http server for prometheus metrics
+// RunMetricsServer runs http server for prometheus metrics of Default registry
+// it blocks until server fails, use Server to stop it
 func RunMetricsServer(address string) error {
-	http.Handle(prometheusMetricsPath, promhttp.Handler())
+	return RunRegistryMetricsServer(address, Default)
+}
+
+// RunRegistryMetricsServer runs http server for prometheus metrics of registry
+// it blocks until server fails, use Server to stop it
+func RunRegistryMetricsServer(address string, registry *Registry) error {
+	serveMux := http.NewServeMux()
+	serveMux.Handle(MetricsPath, registry.Handler())
+
+	return http.ListenAndServe(address, serveMux)
+}
+
+// ServerOption configures Server created by NewServer
+type ServerOption func(*Server)
+
+// WithRegistry sets registry of which metrics are served, Default is used by default
+func WithRegistry(registry *Registry) ServerOption {
+	return func(s *Server) {
+		s.registry = registry
+	}
+}
+
+// WithBasicAuth protects metrics with basic auth, empty username disables it
+// requests are authorized if either basic auth or bearer token matches
+func WithBasicAuth(username string, password string) ServerOption {
+	return func(s *Server) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithBearerToken protects metrics with bearer token, empty token disables it
+func WithBearerToken(token string) ServerOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithTLS serves metrics over https with certificate and key from files, empty certFile disables it
+func WithTLS(certFile string, keyFile string) ServerOption {
+	return func(s *Server) {
+		s.certFile = certFile
+		s.keyFile = keyFile
+	}
+}
+
+// WithLogger sets logger of serving and stopping errors, standard log package is used by default
+func WithLogger(sugarLogger *zap.SugaredLogger) ServerOption {
+	return func(s *Server) {
+		s.logger = sugarLogger
+	}
+}
+
+// WithShutdownTimeout limits time which is given to Stop to finish active scrapes, 5s by default
+func WithShutdownTimeout(timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// Server serves prometheus metrics at MetricsPath
+// it can be started on its own address with Start or its Handler can be added to main router
+type Server struct {
+	server *http.Server
+
+	registry        *Registry
+	username        string
+	password        string
+	token           string
+	certFile        string
+	keyFile         string
+	shutdownTimeout time.Duration
+	logger          *zap.SugaredLogger
+
+	mu       sync.Mutex
+	listener net.Listener
+	done     chan struct{}
+	serveErr error
+}
+
+// NewServer creates Server which listens address after Start
+func NewServer(address string, opts ...ServerOption) *Server {
+	s := &Server{
+		registry:        Default,
+		shutdownTimeout: defaultShutdownTimeout,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	serveMux := http.NewServeMux()
+	serveMux.Handle(MetricsPath, s.Handler())
+	s.server = &http.Server{
+		Addr:              address,
+		Handler:           serveMux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	return s
+}
+
+// Handler returns metrics handler protected with auth of Server, use it to serve metrics on main router:
+//
+//	router.Handle(metrics.MetricsPath, metricsServer.Handler())
+func (s *Server) Handler() http.Handler {
+	handler := s.registry.Handler()
+	if s.username == "" && s.token == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(respWriter http.ResponseWriter, req *http.Request) {
+		if !s.authorized(req) {
+			if s.username != "" {
+				respWriter.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			}
+			http.Error(respWriter, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		handler.ServeHTTP(respWriter, req)
+	})
+}
+
+// authorized returns whether req has valid credentials
+func (s *Server) authorized(req *http.Request) bool {
+	if s.username != "" {
+		if username, password, ok := req.BasicAuth(); ok &&
+			subtle.ConstantTimeCompare([]byte(username), []byte(s.username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(password), []byte(s.password)) == 1 {
+			return true
+		}
+	}
+	if s.token != "" {
+		if token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer "); ok &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Start listens address of Server and serves metrics in background
+// errors of listening and loading of TLS certificate are returned immediately,
+// errors of serving are logged when they happen
+func (s *Server) Start() error {
+	const fn = "metrics.Server.Start"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done != nil {
+		return fmt.Errorf("%s: server is already started", fn)
+	}
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("%s: unable to listen %s: %v", fn, s.server.Addr, err)
+	}
+	if s.certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("%s: unable to load TLS certificate: %v", fn, err)
+		}
+		listener = tls.NewListener(listener, &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			MinVersion:   tls.VersionTLS12,
+		})
+	}
+
+	s.listener = listener
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			s.errorf("%s: metrics server at %s failed: %v", fn, listener.Addr(), err)
+			s.mu.Lock()
+			s.serveErr = err
+			s.mu.Unlock()
+		}
+	}()
+
+	return nil
+}
+
+// Addr returns address which Server listens, it is useful when port is chosen by system
+// it returns nil if Server is not started
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Addr()
+}
+
+// Shutdown gracefully stops Server, it returns error of serving if Server has failed
+func (s *Server) Shutdown(ctx context.Context) error {
+	const fn = "metrics.Server.Shutdown"
+
+	if err := s.shutdown(ctx); err != nil {
+		return fmt.Errorf("%s: %v", fn, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.serveErr != nil {
+		return fmt.Errorf("%s: server error: %v", fn, s.serveErr)
+	}
+
+	return nil
+}
+
+// Stop gracefully stops Server with shutdown timeout, it can be used as closer of server.Server.Run
+// errors of stopping are logged, errors of serving have been logged by Start
+func (s *Server) Stop() {
+	const fn = "metrics.Server.Stop"
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	if err := s.shutdown(ctx); err != nil {
+		s.errorf("%s: %v", fn, err)
+	}
+}
+
+// shutdown gracefully stops http server and waits for serving goroutine
+func (s *Server) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("unable to shutdown server: %v", err)
+	}
+	<-done
+
+	return nil
+}
+
+// errorf writes error to logger of Server or to standard logger if it is not set
+func (s *Server) errorf(template string, args ...interface{}) {
+	if s.logger != nil {
+		s.logger.Errorf(template, args...)
+		return
+	}
 
-	return http.ListenAndServe(address, nil)
+	log.Printf(template, args...)
 }
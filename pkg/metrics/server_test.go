@@ -0,0 +1,221 @@
+package metrics
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestServerAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []ServerOption
+		setAuth    func(req *http.Request)
+		wantStatus int
+	}{
+		{
+			name:       "without auth",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "basic auth",
+			opts:       []ServerOption{WithBasicAuth("prometheus", "secret")},
+			setAuth:    func(req *http.Request) { req.SetBasicAuth("prometheus", "secret") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "wrong basic auth",
+			opts:       []ServerOption{WithBasicAuth("prometheus", "secret")},
+			setAuth:    func(req *http.Request) { req.SetBasicAuth("prometheus", "wrong") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "bearer token",
+			opts:       []ServerOption{WithBasicAuth("prometheus", "secret"), WithBearerToken("token")},
+			setAuth:    func(req *http.Request) { req.Header.Set("Authorization", "Bearer token") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "without token",
+			opts:       []ServerOption{WithBearerToken("token")},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "empty credentials disable auth",
+			opts:       []ServerOption{WithBasicAuth("", ""), WithBearerToken("")},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricsServer := NewServer("", append(tt.opts, WithRegistry(NewRegistry()))...)
+
+			req := httptest.NewRequest(http.MethodGet, MetricsPath, nil)
+			if tt.setAuth != nil {
+				tt.setAuth(req)
+			}
+			recorder := httptest.NewRecorder()
+			metricsServer.Handler().ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestServerStartShutdown(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t)
+
+	tests := []struct {
+		name   string
+		opts   []ServerOption
+		scheme string
+	}{
+		{
+			name:   "http",
+			scheme: "http",
+		},
+		{
+			name:   "https",
+			opts:   []ServerOption{WithTLS(certFile, keyFile)},
+			scheme: "https",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricsServer := NewServer("127.0.0.1:0", append(tt.opts, WithRegistry(NewRegistry()))...)
+			if err := metricsServer.Start(); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			if err := metricsServer.Start(); err == nil {
+				t.Errorf("second Start() error = nil")
+			}
+
+			client := &http.Client{
+				Timeout:   5 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+			}
+			url := tt.scheme + "://" + metricsServer.Addr().String() + MetricsPath
+			resp, err := client.Get(url)
+			if err != nil {
+				t.Fatalf("unable to get metrics: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := metricsServer.Shutdown(ctx); err != nil {
+				t.Fatalf("Shutdown() error = %v", err)
+			}
+			if _, err := client.Get(url); err == nil {
+				t.Errorf("server responds after Shutdown")
+			}
+		})
+	}
+}
+
+func TestServerStartErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+
+	if err := NewServer(listener.Addr().String()).Start(); err == nil {
+		t.Errorf("Start() on busy address error = nil")
+	}
+	if err := NewServer("127.0.0.1:0", WithTLS("missing.crt", "missing.key")).Start(); err == nil {
+		t.Errorf("Start() with missing certificate error = nil")
+	}
+
+	// stopping of not started server is noop
+	NewServer("127.0.0.1:0").Stop()
+}
+
+func TestServerLogsServeErrors(t *testing.T) {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	metricsServer := NewServer("127.0.0.1:0", WithRegistry(NewRegistry()), WithLogger(zap.New(core).Sugar()))
+	if err := metricsServer.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// closed listener makes server fail at runtime
+	_ = metricsServer.listener.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if logs.Len() != 1 {
+		t.Fatalf("logged errors = %v, want 1 error of serving", logs.All())
+	}
+
+	// error of serving is returned by Shutdown, but Stop doesn't log it again
+	metricsServer.Stop()
+	if logs.Len() != 1 {
+		t.Errorf("logged errors after Stop = %v, want 1", logs.All())
+	}
+	if err := metricsServer.Shutdown(context.Background()); err == nil {
+		t.Errorf("Shutdown() of failed server error = nil")
+	}
+}
+
+// writeTestCertificate writes self-signed certificate for 127.0.0.1 and its key to files
+func writeTestCertificate(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("unable to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "metrics.crt")
+	keyFile := filepath.Join(dir, "metrics.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0o600); err != nil {
+		t.Fatalf("unable to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0o600); err != nil {
+		t.Fatalf("unable to write key: %v", err)
+	}
+
+	return certFile, keyFile
+}
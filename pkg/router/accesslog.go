@@ -0,0 +1,154 @@
+package router
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/urfave/negroni"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/levinishka/scratch/pkg/logger"
+	"github.com/levinishka/scratch/pkg/metrics"
+)
+
+// access log fields
+const (
+	AccessLogFieldMethod     = "method"
+	AccessLogFieldRoute      = "route"
+	AccessLogFieldPath       = "path"
+	AccessLogFieldStatus     = "status"
+	AccessLogFieldBytes      = "bytes"
+	AccessLogFieldDuration   = "duration"
+	AccessLogFieldUserAgent  = "user_agent"
+	AccessLogFieldRequestID  = "request_id"
+	AccessLogFieldRemoteAddr = "remote_addr"
+)
+
+const (
+	accessLogMessage = "access"
+
+	defaultSlowThreshold = time.Second
+)
+
+// AccessLogOptions configures AccessLogMiddleware
+type AccessLogOptions struct {
+	// SampleEvery logs one of every SampleEvery successful requests, 0 and 1 mean every request
+	// requests with error statuses and slow requests are always logged
+	SampleEvery uint64
+	// SlowThreshold is a duration after which request is slow, slow requests are logged with warn level
+	// zero value means defaultSlowThreshold, negative value disables slow requests logging
+	SlowThreshold time.Duration
+	// Fields are names of fields which are written, all fields are written if it is empty
+	Fields []string
+}
+
+// DefaultAccessLogOptions returns options which log every request with all fields
+func DefaultAccessLogOptions() AccessLogOptions {
+	return AccessLogOptions{SlowThreshold: defaultSlowThreshold}
+}
+
+// AccessLogMiddleware returns middleware which writes every request to sugarLogger
+// statuses 4xx are logged with warn level, statuses 5xx with error level
+// request ID is written if RequestIDMiddleware is used before this middleware
+// if sugarLogger is nil, logger.Fallback is used
+func AccessLogMiddleware(sugarLogger *zap.SugaredLogger, options AccessLogOptions) mux.MiddlewareFunc {
+	slowThreshold := options.SlowThreshold
+	if slowThreshold == 0 {
+		slowThreshold = defaultSlowThreshold
+	}
+
+	fields := make(map[string]bool)
+	if len(options.Fields) == 0 {
+		for _, field := range []string{
+			AccessLogFieldMethod, AccessLogFieldRoute, AccessLogFieldPath, AccessLogFieldStatus,
+			AccessLogFieldBytes, AccessLogFieldDuration, AccessLogFieldUserAgent, AccessLogFieldRequestID,
+			AccessLogFieldRemoteAddr,
+		} {
+			fields[field] = true
+		}
+	}
+	for _, field := range options.Fields {
+		fields[field] = true
+	}
+
+	if sugarLogger == nil {
+		sugarLogger = logger.Fallback()
+	}
+	// caller is always this middleware, so it is useless
+	accessLogger := sugarLogger.Desugar().WithOptions(zap.WithCaller(false))
+
+	// requests counts successful requests for sampling
+	var requests atomic.Uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			responseWriter := negroni.NewResponseWriter(w)
+			next.ServeHTTP(responseWriter, r)
+			duration := time.Since(start)
+
+			status := responseWriter.Status()
+			// net/http writes 200 if handler writes nothing
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := zapcore.InfoLevel
+			switch {
+			case status >= http.StatusInternalServerError:
+				level = zapcore.ErrorLevel
+			case status >= http.StatusBadRequest:
+				level = zapcore.WarnLevel
+			case slowThreshold > 0 && duration >= slowThreshold:
+				level = zapcore.WarnLevel
+			case options.SampleEvery > 1 && (requests.Add(1)-1)%options.SampleEvery != 0:
+				return
+			}
+
+			if checked := accessLogger.Check(level, accessLogMessage); checked != nil {
+				checked.Write(accessLogFields(fields, r, status, responseWriter.Size(), duration)...)
+			}
+		})
+	}
+}
+
+// accessLogFields returns selected fields of request r
+func accessLogFields(fields map[string]bool, r *http.Request, status int, size int, duration time.Duration) []zap.Field {
+	result := make([]zap.Field, 0, len(fields))
+
+	if fields[AccessLogFieldMethod] {
+		result = append(result, zap.String(AccessLogFieldMethod, r.Method))
+	}
+	if fields[AccessLogFieldRoute] {
+		if template, ok := metrics.RoutePath(r); ok {
+			result = append(result, zap.String(AccessLogFieldRoute, template))
+		}
+	}
+	if fields[AccessLogFieldPath] {
+		result = append(result, zap.String(AccessLogFieldPath, r.URL.Path))
+	}
+	if fields[AccessLogFieldStatus] {
+		result = append(result, zap.Int(AccessLogFieldStatus, status))
+	}
+	if fields[AccessLogFieldBytes] {
+		result = append(result, zap.Int(AccessLogFieldBytes, size))
+	}
+	if fields[AccessLogFieldDuration] {
+		result = append(result, zap.Duration(AccessLogFieldDuration, duration))
+	}
+	if fields[AccessLogFieldUserAgent] {
+		result = append(result, zap.String(AccessLogFieldUserAgent, r.UserAgent()))
+	}
+	if fields[AccessLogFieldRequestID] {
+		if requestID := RequestIDFromContext(r.Context()); requestID != "" {
+			result = append(result, zap.String(AccessLogFieldRequestID, requestID))
+		}
+	}
+	if fields[AccessLogFieldRemoteAddr] {
+		result = append(result, zap.String(AccessLogFieldRemoteAddr, r.RemoteAddr))
+	}
+
+	return result
+}
@@ -0,0 +1,127 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		options    AccessLogOptions
+		path       string
+		requests   int
+		wantLogs   int
+		wantLevel  zapcore.Level
+		wantFields map[string]interface{}
+	}{
+		{
+			name:      "every request with all fields",
+			options:   DefaultAccessLogOptions(),
+			path:      "/users/1",
+			requests:  3,
+			wantLogs:  3,
+			wantLevel: zapcore.InfoLevel,
+			wantFields: map[string]interface{}{
+				AccessLogFieldMethod:     http.MethodGet,
+				AccessLogFieldRoute:      "/users/{id}",
+				AccessLogFieldPath:       "/users/1",
+				AccessLogFieldStatus:     int64(http.StatusOK),
+				AccessLogFieldBytes:      int64(2),
+				AccessLogFieldUserAgent:  "test-agent",
+				AccessLogFieldRemoteAddr: "192.0.2.1:1234",
+			},
+		},
+		{
+			name:      "sampled successful requests",
+			options:   AccessLogOptions{SampleEvery: 3},
+			path:      "/users/1",
+			requests:  7,
+			wantLogs:  3,
+			wantLevel: zapcore.InfoLevel,
+		},
+		{
+			name:      "errors are not sampled",
+			options:   AccessLogOptions{SampleEvery: 100},
+			path:      "/fail",
+			requests:  3,
+			wantLogs:  3,
+			wantLevel: zapcore.ErrorLevel,
+		},
+		{
+			name:      "slow requests are not sampled",
+			options:   AccessLogOptions{SampleEvery: 100, SlowThreshold: time.Nanosecond},
+			path:      "/users/1",
+			requests:  2,
+			wantLogs:  2,
+			wantLevel: zapcore.WarnLevel,
+		},
+		{
+			name:      "selected fields",
+			options:   AccessLogOptions{Fields: []string{AccessLogFieldStatus, AccessLogFieldRoute}},
+			path:      "/users/1",
+			requests:  1,
+			wantLogs:  1,
+			wantLevel: zapcore.InfoLevel,
+			wantFields: map[string]interface{}{
+				AccessLogFieldStatus: int64(http.StatusOK),
+				AccessLogFieldRoute:  "/users/{id}",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.DebugLevel)
+
+			router := NewRouter(false, WithLogger(zap.New(core).Sugar()), WithAccessLog(tt.options))
+			router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			})
+			router.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			for i := 0; i < tt.requests; i++ {
+				request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				request.Header.Set("User-Agent", "test-agent")
+				request.RemoteAddr = "192.0.2.1:1234"
+				router.ServeHTTP(httptest.NewRecorder(), request)
+			}
+
+			entries := logs.FilterMessage(accessLogMessage).All()
+			if len(entries) != tt.wantLogs {
+				t.Fatalf("got %d access log entries, want %d", len(entries), tt.wantLogs)
+			}
+			for _, entry := range entries {
+				if entry.Level != tt.wantLevel {
+					t.Errorf("access log level = %s, want %s", entry.Level, tt.wantLevel)
+				}
+			}
+			if tt.wantFields == nil || len(entries) == 0 {
+				return
+			}
+
+			fields := entries[0].ContextMap()
+			if _, ok := fields[AccessLogFieldRequestID]; ok != (len(tt.options.Fields) == 0) {
+				t.Errorf("access log request_id presence = %v", ok)
+			}
+			delete(fields, AccessLogFieldRequestID)
+			delete(fields, AccessLogFieldDuration)
+			if len(fields) != len(tt.wantFields) {
+				t.Errorf("access log fields = %v, want %v", fields, tt.wantFields)
+			}
+			for key, want := range tt.wantFields {
+				if fields[key] != want {
+					t.Errorf("access log field %s = %v, want %v", key, fields[key], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package router
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/levinishka/scratch/pkg/logger"
+	"github.com/levinishka/scratch/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// RequestIDHeader is a header which is used to accept and return request ID
+	RequestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLength limits length of accepted request ID, longer IDs are replaced with generated ones
+	maxRequestIDLength = 128
+)
+
+// requestIDKey is a key of request ID in context
+type requestIDKey struct{}
+
+// RequestIDMiddleware returns middleware which accepts request ID from X-Request-ID header or generates new one
+// and returns it in response header
+// child of sugarLogger with request ID, method, route template and remote address is stored in request context,
+// it can be got with logger.FromContext
+func RequestIDMiddleware(sugarLogger *zap.SugaredLogger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !isValidRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			fields := []interface{}{
+				"request_id", requestID,
+				"method", r.Method,
+				"remote_addr", r.RemoteAddr,
+			}
+			if template, ok := metrics.RoutePath(r); ok {
+				fields = append(fields, "route", template)
+			}
+
+			ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+			ctx = logger.WithContext(ctx, sugarLogger.With(fields...))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequestIDFromContext returns request ID stored by RequestIDMiddleware or empty string
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// isValidRequestID reports whether requestID can be used in logs and headers as is
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		// only printable ASCII characters without spaces are accepted
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestID generates random request ID
+func newRequestID() string {
+	id := make([]byte, 16)
+	// crypto/rand.Read never returns error on supported platforms
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
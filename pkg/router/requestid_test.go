@@ -0,0 +1,79 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/levinishka/scratch/pkg/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		wantRequestID string
+	}{
+		{
+			name:          "accepted",
+			requestID:     "abc-123",
+			wantRequestID: "abc-123",
+		},
+		{
+			name: "generated",
+		},
+		{
+			name:      "invalid replaced",
+			requestID: "with space",
+		},
+		{
+			name:      "too long replaced",
+			requestID: strings.Repeat("a", maxRequestIDLength+1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+
+			var contextRequestID string
+			router := NewRouter(false)
+			router.Use(RequestIDMiddleware(zap.New(core).Sugar()))
+			router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				contextRequestID = RequestIDFromContext(r.Context())
+				logger.FromContext(r.Context()).Info("handled")
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tt.requestID != "" {
+				request.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			requestID := recorder.Header().Get(RequestIDHeader)
+			if tt.wantRequestID != "" && requestID != tt.wantRequestID {
+				t.Errorf("%s = %q, want %q", RequestIDHeader, requestID, tt.wantRequestID)
+			}
+			if tt.wantRequestID == "" && (len(requestID) != 32 || requestID == tt.requestID) {
+				t.Errorf("%s = %q, want generated ID", RequestIDHeader, requestID)
+			}
+			if contextRequestID != requestID {
+				t.Errorf("RequestIDFromContext() = %q, want %q", contextRequestID, requestID)
+			}
+
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("got %d log entries, want 1", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			if fields["request_id"] != requestID || fields["method"] != http.MethodGet ||
+				fields["route"] != "/users/{id}" || fields["remote_addr"] != request.RemoteAddr {
+				t.Errorf("log fields = %v", fields)
+			}
+		})
+	}
+}
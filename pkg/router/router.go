@@ -1,29 +1,142 @@
 package router
 
 import (
+	"net/http"
 	"net/http/pprof"
 
 	"github.com/gorilla/mux"
+	"github.com/levinishka/scratch/pkg/logger"
 	"github.com/levinishka/scratch/pkg/metrics"
+	"go.uber.org/zap"
 )
 
+// LogLevelPath is a path of logger level handler
+const LogLevelPath = "/debug/loglevel"
+
+// Option configures router created by NewRouter
+type Option func(*options)
+
+// options stores router configuration
+type options struct {
+	logger           *zap.SugaredLogger
+	accessLog        bool
+	accessLogOptions AccessLogOptions
+	metrics          *metrics.Registry
+	logLevel         *logger.Level
+}
+
+// newOptions returns default options changed by opts
+func newOptions(opts []Option) options {
+	o := options{
+		accessLog:        true,
+		accessLogOptions: DefaultAccessLogOptions(),
+		metrics:          metrics.Default,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithLogger sets logger of router: every request gets request ID and child of sugarLogger in its context
+// and access log is written to sugarLogger, logger.Fallback is used by default
+func WithLogger(sugarLogger *zap.SugaredLogger) Option {
+	return func(o *options) {
+		o.logger = sugarLogger
+	}
+}
+
+// WithAccessLog sets access log options
+func WithAccessLog(accessLogOptions AccessLogOptions) Option {
+	return func(o *options) {
+		o.accessLog = true
+		o.accessLogOptions = accessLogOptions
+	}
+}
+
+// WithoutAccessLog disables access log
+func WithoutAccessLog() Option {
+	return func(o *options) {
+		o.accessLog = false
+	}
+}
+
+// WithMetrics sets registry of http metrics, metrics.Default is used by default
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(o *options) {
+		o.metrics = registry
+	}
+}
+
+// WithLogLevel adds level handler at LogLevelPath to router created by NewRouterWithPprof,
+// level can be read with GET and changed with PUT request
+func WithLogLevel(level *logger.Level) Option {
+	return func(o *options) {
+		o.logLevel = level
+	}
+}
+
 // NewRouter creates new mux router
-func NewRouter(strictSlash bool) *mux.Router {
+// access log is written to logger.Fallback unless WithLogger or WithoutAccessLog option is used
+// requests which do not match any route are measured with metrics.NotFoundRoute and metrics.MethodNotAllowedRoute
+// paths, wrap custom NotFoundHandler with Registry.RouteHandler to keep them
+func NewRouter(strictSlash bool, opts ...Option) *mux.Router {
+	o := newOptions(opts)
+	if o.logger == nil {
+		o.logger = logger.Fallback()
+	}
+
 	router := mux.NewRouter().StrictSlash(strictSlash)
 	// always use prometheus metrics middleware
-	router.Use(metrics.PrometheusMiddleware)
+	router.Use(o.metrics.Middleware)
+	logMiddlewares := []mux.MiddlewareFunc{RequestIDMiddleware(o.logger)}
+	if o.accessLog {
+		logMiddlewares = append(logMiddlewares, AccessLogMiddleware(o.logger, o.accessLogOptions))
+	}
+	router.Use(logMiddlewares...)
+
+	// middlewares are not applied to requests which do not match any route,
+	// so not found and method not allowed handlers are wrapped with them and measured with synthetic paths
+	router.NotFoundHandler = o.metrics.RouteHandler(metrics.NotFoundRoute,
+		wrapHandler(http.NotFoundHandler(), logMiddlewares))
+	router.MethodNotAllowedHandler = o.metrics.RouteHandler(metrics.MethodNotAllowedRoute,
+		wrapHandler(http.HandlerFunc(methodNotAllowed), logMiddlewares))
+
 	return router
 }
 
+// wrapHandler wraps handler with middlewares, the first middleware is the outermost one
+func wrapHandler(handler http.Handler, middlewares []mux.MiddlewareFunc) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// methodNotAllowed responds with 405 status like default handler of mux router
+func methodNotAllowed(respWriter http.ResponseWriter, req *http.Request) {
+	respWriter.WriteHeader(http.StatusMethodNotAllowed)
+}
+
 // NewRouterWithPprof creates new mux router and register pprof handlers
-// path for all pprof handlers has /debug/pprof/ prefix
-func NewRouterWithPprof(strictSlash bool) *mux.Router {
-	router := NewRouter(strictSlash)
+// path for all pprof handlers has /debug/pprof/ prefix, logger level handler is added with WithLogLevel option
+func NewRouterWithPprof(strictSlash bool, opts ...Option) *mux.Router {
+	router := NewRouter(strictSlash, opts...)
 	addPprof(router)
+	if level := newOptions(opts).logLevel; level != nil {
+		AddLogLevel(router, level)
+	}
 
 	return router
 }
 
+// AddLogLevel adds logger level handler to router
+func AddLogLevel(router *mux.Router, level *logger.Level) {
+	router.Handle(LogLevelPath, level).Methods(http.MethodGet, http.MethodPut)
+}
+
 // addPprof adds pprof handlers to router
 func addPprof(router *mux.Router) {
 	router.HandleFunc("/debug/pprof/", pprof.Index)
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/levinishka/scratch/pkg/logger"
+	"github.com/levinishka/scratch/pkg/metrics"
+)
+
+func TestNewRouterUnmatchedRoutes(t *testing.T) {
+	registry := metrics.NewRegistry()
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	router := NewRouter(true, WithMetrics(registry), WithLogger(zap.New(core).Sugar()))
+	router.HandleFunc("/users/{id}", func(http.ResponseWriter, *http.Request) {}).Methods(http.MethodGet)
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantMetric string
+	}{
+		{
+			method:     http.MethodGet,
+			path:       "/users/1",
+			wantStatus: http.StatusOK,
+			wantMetric: `http_requests_total{code="200",method="GET",path="/users/{id}"} 1`,
+		},
+		{
+			method:     http.MethodGet,
+			path:       "/unknown",
+			wantStatus: http.StatusNotFound,
+			wantMetric: `http_requests_total{code="404",method="GET",path="not_found"} 1`,
+		},
+		{
+			method:     http.MethodDelete,
+			path:       "/users/1",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantMetric: `http_requests_total{code="405",method="DELETE",path="method_not_allowed"} 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+		if recorder.Code != tt.wantStatus {
+			t.Errorf("%s %s status = %d, want %d", tt.method, tt.path, recorder.Code, tt.wantStatus)
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	for _, tt := range tests {
+		if !strings.Contains(string(body), tt.wantMetric) {
+			t.Errorf("metrics do not contain %s:\n%s", tt.wantMetric, body)
+		}
+	}
+
+	// unmatched requests get request IDs and are written to access log too
+	accessLogs := logs.FilterMessage("access")
+	if accessLogs.Len() != len(tests) {
+		t.Fatalf("access log entries = %v, want %d", accessLogs.All(), len(tests))
+	}
+	for _, entry := range accessLogs.All() {
+		if _, ok := entry.ContextMap()[AccessLogFieldRequestID]; !ok {
+			t.Errorf("access log entry %v has no request id", entry.ContextMap())
+		}
+	}
+}
+
+func TestMetricsMiddlewareWithoutMux(t *testing.T) {
+	registry := metrics.NewRegistry()
+
+	serveMux := http.NewServeMux()
+	serveMux.HandleFunc("/ping", func(respWriter http.ResponseWriter, req *http.Request) {
+		_, _ = respWriter.Write([]byte("pong"))
+	})
+
+	recorder := httptest.NewRecorder()
+	registry.Middleware(serveMux).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	if recorder.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	recorder = httptest.NewRecorder()
+	registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	if want := `http_requests_total{code="200",method="GET",path="unmatched"} 1`; !strings.Contains(string(body), want) {
+		t.Errorf("metrics do not contain %s:\n%s", want, body)
+	}
+}
+
+func TestNewRouterWithPprofLogLevel(t *testing.T) {
+	level := logger.NewLevel(zapcore.InfoLevel)
+
+	tests := []struct {
+		name       string
+		opts       []Option
+		wantStatus int
+	}{
+		{"without level", nil, http.StatusNotFound},
+		{"with level", []Option{WithLogLevel(level)}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithMetrics(metrics.NewRegistry()), WithoutAccessLog()}, tt.opts...)
+			router := NewRouterWithPprof(true, opts...)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LogLevelPath, nil))
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("GET %s status = %d, want %d", LogLevelPath, recorder.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -14,13 +14,14 @@ import (
 type Server struct {
 	Server *http.Server
 
-	gracefulShutdownTimeout int64
+	gracefulShutdownTimeout time.Duration
 
 	logger *zap.SugaredLogger
 }
 
 // NewServer creates Server
-func NewServer(server *http.Server, sugarLogger *zap.SugaredLogger, gracefulShutdownTimeout int64) *Server {
+// gracefulShutdownTimeout limits time which is given to server to finish active requests on shutdown
+func NewServer(server *http.Server, sugarLogger *zap.SugaredLogger, gracefulShutdownTimeout time.Duration) *Server {
 	return &Server{
 		Server:                  server,
 		gracefulShutdownTimeout: gracefulShutdownTimeout,
@@ -30,6 +31,7 @@ func NewServer(server *http.Server, sugarLogger *zap.SugaredLogger, gracefulShut
 
 // Run runs server and wait os.Interrupt signal to gracefully shutdown server
 // and close all resources with closers
+// logger is synced after closers, so entries buffered by asynchronous writers are written
 func (s *Server) Run(ctx context.Context, closers ...func()) {
 	const fn = "Run"
 
@@ -49,7 +51,7 @@ func (s *Server) Run(ctx context.Context, closers ...func()) {
 		const fn = "closer"
 		closing = true
 
-		shutdownCtx, cancel := context.WithTimeout(ctx, time.Duration(s.gracefulShutdownTimeout)*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(ctx, s.gracefulShutdownTimeout)
 		defer cancel()
 
 		if err := s.Server.Shutdown(shutdownCtx); err != nil {
@@ -73,4 +75,7 @@ func (s *Server) gracefulShutdown(closers ...func()) {
 	for _, closer := range closers {
 		closer()
 	}
+
+	// sync errors of stdout and stderr are not useful
+	_ = s.logger.Sync()
 }
@@ -0,0 +1,192 @@
+// Package units provides Duration and ByteSize values which are read from strings like "5s" and "10MiB"
+package units
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration value which is read from strings like "250ms", "5s" or "1h30m"
+// in JSON, YAML, TOML, environment variables and flags
+type Duration time.Duration
+
+// Duration returns value as time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// String implements fmt.Stringer
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(strings.TrimSpace(string(text)))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(duration)
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+// only zero is accepted as a number, because numbers without unit are ambiguous
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return d.UnmarshalText([]byte(text))
+	}
+
+	var number float64
+	if err := json.Unmarshal(data, &number); err != nil || number != 0 {
+		return fmt.Errorf("duration must be a string with unit like \"5s\" or \"250ms\", got %s", data)
+	}
+
+	*d = 0
+	return nil
+}
+
+// ByteSize is a size in bytes value which is read from strings like "512", "100KB" or "10MiB"
+// in JSON, YAML, TOML, environment variables and flags
+// KB, MB, GB, TB and PB are powers of 1000, KiB, MiB, GiB, TiB and PiB are powers of 1024
+type ByteSize uint64
+
+// byte size units
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000 * Byte
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+
+	KiB ByteSize = 1024 * Byte
+	MiB ByteSize = 1024 * KiB
+	GiB ByteSize = 1024 * MiB
+	TiB ByteSize = 1024 * GiB
+	PiB ByteSize = 1024 * TiB
+)
+
+// byteSizeUnits stores units in order used for formatting
+var byteSizeUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	{"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"KB", KB},
+	{"B", Byte},
+}
+
+// ParseByteSize parses size with optional unit: "512", "512B", "100KB", "1.5GiB"
+// units are case-insensitive
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	number, unitName := s[:i], strings.TrimSpace(s[i:])
+
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+
+	unit := Byte
+	if unitName != "" {
+		found := false
+		for _, u := range byteSizeUnits {
+			if strings.EqualFold(u.name, unitName) {
+				unit, found = u.size, true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", s, unitName)
+		}
+	}
+
+	size := value * float64(unit)
+	if size > math.MaxUint64 {
+		return 0, fmt.Errorf("invalid byte size %q: value is too big", s)
+	}
+
+	return ByteSize(size), nil
+}
+
+// Bytes returns size in bytes
+func (b ByteSize) Bytes() uint64 {
+	return uint64(b)
+}
+
+// Int returns size in bytes as int, it is useful for standard library options like http.Server.MaxHeaderBytes
+func (b ByteSize) Int() int {
+	if uint64(b) > math.MaxInt {
+		return math.MaxInt
+	}
+
+	return int(b)
+}
+
+// String implements fmt.Stringer and returns size in the largest unit which divides it without remainder
+func (b ByteSize) String() string {
+	if b == 0 {
+		return "0B"
+	}
+
+	for _, u := range byteSizeUnits {
+		if b%u.size == 0 {
+			return strconv.FormatUint(uint64(b/u.size), 10) + u.name
+		}
+	}
+
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = size
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+// numbers are read as size in bytes
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return b.UnmarshalText([]byte(text))
+	}
+
+	var size uint64
+	if err := json.Unmarshal(data, &size); err != nil {
+		return fmt.Errorf("byte size must be a number or a string like \"10MiB\", got %s", data)
+	}
+
+	*b = ByteSize(size)
+	return nil
+}
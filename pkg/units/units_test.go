@@ -0,0 +1,86 @@
+package units
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDurationUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"seconds", `"5s"`, 5 * time.Second, false},
+		{"milliseconds", `"250ms"`, 250 * time.Millisecond, false},
+		{"complex", `"1h30m"`, 90 * time.Minute, false},
+		{"zero", `0`, 0, false},
+		{"number", `5`, 0, true},
+		{"no unit", `"5"`, 0, true},
+		{"bool", `true`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Duration
+			err := json.Unmarshal([]byte(tt.data), &d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && d.Duration() != tt.want {
+				t.Errorf("UnmarshalJSON() got = %v, want %v", d, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ByteSize
+		wantErr bool
+	}{
+		{"512", 512, false},
+		{"512B", 512, false},
+		{"100KB", 100 * KB, false},
+		{"10MiB", 10 * MiB, false},
+		{"10mib", 10 * MiB, false},
+		{"1.5GiB", 1536 * MiB, false},
+		{" 2 TB ", 2 * TB, false},
+		{"", 0, true},
+		{"MiB", 0, true},
+		{"10XB", 0, true},
+		{"-1KB", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseByteSize(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseByteSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseByteSize() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := []struct {
+		size ByteSize
+		want string
+	}{
+		{0, "0B"},
+		{512, "512B"},
+		{10 * MiB, "10MiB"},
+		{100 * KB, "100KB"},
+		{1536 * MiB, "1536MiB"},
+		{1001, "1001B"},
+	}
+	for _, tt := range tests {
+		if got := tt.size.String(); got != tt.want {
+			t.Errorf("String() got = %s, want %s", got, tt.want)
+		}
+	}
+}